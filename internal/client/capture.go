@@ -0,0 +1,94 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CaptureFunc receives failed requests, see WithCaptureOnError.
+type CaptureFunc func(req *http.Request, resp *http.Response, err error)
+
+// capture passes a snapshot of req, which failed with err, to the capture hook if any.
+func (c *Client) capture(req *http.Request, resp *http.Response, err error) {
+	if c.config.CaptureOnError == nil {
+		return
+	}
+	c.config.CaptureOnError(snapshotRequest(req, c.config.Headers, c.config.DynamicUserAgent), resp, err)
+}
+
+// snapshotRequest returns a copy of req, once sent, as it went on the wire: with the default headers
+// and, when it can be replayed, its body.
+func snapshotRequest(req *http.Request, headers map[string]string, userAgent func(ctx context.Context) string) *http.Request {
+	snap := req.Clone(req.Context())
+	applyDefaultHeaders(snap, headers, userAgent)
+
+	snap.Body = http.NoBody
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			snap.Body = body
+		}
+	}
+
+	return snap
+}
+
+// AsCurl renders req as a curl command reproducing it, with its method, URL, headers and body, e.g.
+// to replay a request captured with WithCaptureOnError. Headers are sorted and every argument is
+// quoted for POSIX shells.
+//
+// The body is read from req.GetBody when set, otherwise req.Body is read and replaced by a copy, so
+// req can still be sent. The command includes the headers as is, credentials included.
+func AsCurl(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString("curl")
+
+	if req.Method != "" && req.Method != http.MethodGet {
+		b.WriteString(" -X " + shellQuote(req.Method))
+	}
+	b.WriteString(" " + shellQuote(req.URL.String()))
+
+	for _, k := range slices.Sorted(maps.Keys(req.Header)) {
+		for _, v := range req.Header[k] {
+			b.WriteString(" -H " + shellQuote(k+": "+v))
+		}
+	}
+
+	if body := curlBody(req); len(body) > 0 {
+		b.WriteString(" --data-binary " + shellQuote(string(body)))
+	}
+
+	return b.String()
+}
+
+// curlBody returns the body of req, leaving it readable.
+func curlBody(req *http.Request) []byte {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		defer body.Close()
+
+		data, _ := io.ReadAll(body)
+		return data
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	data, _ := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_CaptureOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	type captured struct {
+		req  *http.Request
+		body string
+		resp *http.Response
+		err  error
+	}
+	var got []captured
+	capture := func(req *http.Request, resp *http.Response, err error) {
+		body, _ := io.ReadAll(req.Body)
+		got = append(got, captured{req: req, body: string(body), resp: resp, err: err})
+	}
+
+	c, err := New(WithBaseURL(srv.URL), WithCaptureOnError(capture))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Post(context.Background(), "/ok", &RequestConfig{Body: strings.NewReader("fine")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	DrainAndClose(resp)
+	if len(got) != 0 {
+		t.Fatalf("Expected successful requests not to be captured, got %d", len(got))
+	}
+
+	resp, err = c.Post(context.Background(), "/fail", &RequestConfig{
+		Body:    strings.NewReader(`{"id":1}`),
+		Headers: map[string]string{"Content-Type": "application/json"},
+	})
+	DrainAndClose(resp)
+	if err == nil {
+		t.Fatalf("Expected an error status to be reported")
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 captured request, got %d", len(got))
+	}
+	capt := got[0]
+	if capt.req.Method != http.MethodPost || capt.req.URL.String() != srv.URL+"/fail" {
+		t.Errorf("Got %s %s, want POST %s/fail", capt.req.Method, capt.req.URL, srv.URL)
+	}
+	if capt.req.Header.Get("Content-Type") != "application/json" || capt.req.Header.Get("User-Agent") != defaultUserAgent {
+		t.Errorf("Got headers %v, want the request and default headers", capt.req.Header)
+	}
+	if capt.body != `{"id":1}` {
+		t.Errorf("Got body %q, want %q", capt.body, `{"id":1}`)
+	}
+	if capt.resp == nil || capt.resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected the 500 response to be captured, got %v", capt.resp)
+	}
+	if capt.err != err {
+		t.Errorf("Got error %v, want %v", capt.err, err)
+	}
+
+	t.Run("transport error", func(t *testing.T) {
+		var gotResp *http.Response
+		var gotErr error
+		c, _ := New(
+			WithCustomDoer(doerFunc(func(*http.Request) (*http.Response, error) { return nil, errors.New("connection refused") })),
+			WithCaptureOnError(func(_ *http.Request, resp *http.Response, err error) { gotResp, gotErr = resp, err }),
+		)
+
+		if _, err := c.Get(context.Background(), "https://example.com", nil); err == nil {
+			t.Fatalf("Expected a transport error")
+		}
+		if gotErr == nil || gotResp != nil {
+			t.Errorf("Got response %v and error %v, want only the error", gotResp, gotErr)
+		}
+	})
+
+	t.Run("caller-built request", func(t *testing.T) {
+		got = nil
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/fail", strings.NewReader("raw"))
+
+		resp, err := c.Do(req)
+		DrainAndClose(resp)
+		if err == nil {
+			t.Fatalf("Expected an error status to be reported")
+		}
+		if len(got) != 1 || got[0].body != "raw" || got[0].err != err {
+			t.Errorf("Got %d captured requests, want the failed one with its body", len(got))
+		}
+	})
+}
+
+func TestAsCurl(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		body    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "get", method: http.MethodGet, url: "https://example.com/a?b=c", want: `curl 'https://example.com/a?b=c'`},
+		{
+			name:    "post with body",
+			method:  http.MethodPost,
+			url:     "https://example.com/items",
+			body:    `{"name":"it's"}`,
+			headers: map[string]string{"X-B": "2", "Content-Type": "application/json"},
+			want:    `curl -X 'POST' 'https://example.com/items' -H 'Content-Type: application/json' -H 'X-B: 2' --data-binary '{"name":"it'\''s"}'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, _ := http.NewRequest(tt.method, tt.url, body)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			if got := AsCurl(req); got != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("streamed body stays readable", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://example.com", io.NopCloser(strings.NewReader("data")))
+
+		if got, want := AsCurl(req), `curl -X 'PUT' 'https://example.com' --data-binary 'data'`; got != want {
+			t.Errorf("Got %s, want %s", got, want)
+		}
+		if body, _ := io.ReadAll(req.Body); string(body) != "data" {
+			t.Errorf("Got body %q after rendering, want %q", body, "data")
+		}
+	})
+}
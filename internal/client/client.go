@@ -3,17 +3,33 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/glwbr/brisa/pkg/logger"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	defaultTimeout   = 10 * time.Second
 	defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+	// Dialer settings mirroring http.DefaultTransport, used by the per-client base transport.
+	defaultDialTimeout = 30 * time.Second
+	defaultKeepAlive   = 30 * time.Second
+
+	// Transport settings of http.DefaultTransport, which the base transport is cloned from.
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultExpectContinueTimeout = 1 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
 )
 
 var defaultClient *Client
@@ -25,46 +41,221 @@ type Doer interface {
 
 // Client is an HTTP client with support for base URLs, middleware chains, and logging.
 type Client struct {
-	doer    Doer
-	baseURL *url.URL
-	logger  logger.Logger
-	config  *ClientConfig
+	doer          Doer
+	baseURL       *url.URL
+	baseURLFunc   func(ctx context.Context) (*url.URL, error)
+	resolveRef    bool
+	defaultParams url.Values
+	logger        logger.Logger
+	userInfo      *url.Userinfo
+	config        *ClientConfig
+	flight        *singleflight.Group
+	health        *hostHealth
+
+	// transport is the connection pool to release on Close, if known.
+	transport interface{ CloseIdleConnections() }
+	// chain is the transport of the underlying http.Client, if any. See Transport.
+	chain  http.RoundTripper
+	closed atomic.Bool
 }
 
 // New creates a Client with the provided options.
 // It uses sensible defaults that can be overridden with ClientOption functions.
+// Invalid option values are ignored, and logged as warnings, unless WithStrictValidation is
+// given, in which case all of them are reported in the returned error.
 func New(opts ...ClientOption) (*Client, error) {
+	return newClient(buildConfig(opts...))
+}
+
+// With returns a new Client derived from c, with opts applied on top of a copy of c's configuration.
+// The original client is left untouched, which makes it easy to build variants such as per-tenant
+// clients that only differ in their auth headers.
+//
+// The derived client shares with c:
+//   - the cookie jar, so cookies set through either client are visible to both
+//   - the logger and the custom Doer, if any
+//
+// Everything else is copied, notably headers and query parameters, so changing them on one client
+// doesn't affect the other. The derived client builds its own transport chain and connection pool,
+// unless c uses WithSharedTransport, in which case the connection pool is shared too.
+func (c *Client) With(opts ...ClientOption) (*Client, error) {
+	cfg := c.config.clone()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return newClient(cfg)
+}
+
+// newClient builds a Client from a fully applied configuration.
+func newClient(cfg *ClientConfig) (*Client, error) {
 	var doer Doer
-	cfg := buildConfig(opts...)
 
-	if cfg.CustomDoer != nil {
+	if cfg.StrictValidation && len(cfg.errs) > 0 {
+		return nil, fmt.Errorf("invalid client options: %w", errors.Join(cfg.errs...))
+	}
+
+	for _, err := range cfg.errs {
+		cfg.logger().Warn("Ignoring invalid client option", logger.ErrorKey, err.Error())
+	}
+
+	if cfg.RequireBaseURL && cfg.BaseURL == nil && cfg.BaseURLFunc == nil {
+		return nil, errors.New("a valid base URL is required")
+	}
+
+	if cfg.BlockPrivateNetworks && cfg.CustomDoer != nil {
+		// The Doer dials on its own, out of reach of the check on the resolved address.
+		return nil, errors.New("WithBlockPrivateNetworks cannot be combined with WithCustomDoer")
+	}
+
+	if cfg.BlockPrivateNetworks && cfg.HTTPClient != nil {
+		// The protection lives in the dialer of our own base transport, which isn't used with
+		// WithHTTPClient: refuse rather than silently dropping it.
+		return nil, errors.New("WithBlockPrivateNetworks cannot be combined with WithHTTPClient")
+	}
+
+	if cfg.BlockPrivateNetworks && cfg.UnixSocket != "" {
+		return nil, errors.New("WithBlockPrivateNetworks cannot be combined with WithUnixSocket")
+	}
+
+	if cfg.BlockPrivateNetworks && cfg.SharedTransport != nil && cfg.HTTPClient == nil {
+		return nil, errors.New("WithBlockPrivateNetworks cannot be combined with WithSharedTransport")
+	}
+
+	if cfg.Jar == nil && cfg.CookieJarEnabled {
+		// cookiejar.New never fails without options.
+		cfg.Jar, _ = cookiejar.New(nil)
+	}
+
+	cfg.health = nil
+	if cfg.HealthWindow > 0 {
+		cfg.health = newHostHealth(cfg.HealthWindow)
+	}
+
+	var transport interface{ CloseIdleConnections() }
+
+	switch {
+	case cfg.CustomDoer != nil:
 		doer = cfg.CustomDoer
-	} else {
-		doer = createDefaultDoer(cfg)
+	case cfg.HTTPClient != nil:
+		doer = wrapHTTPClient(cfg)
+		transport, _ = cfg.HTTPClient.Transport.(interface{ CloseIdleConnections() })
+	case cfg.SharedTransport != nil:
+		// The transport belongs to the caller, its idle connections aren't ours to close.
+		doer = createDefaultDoer(cfg, cfg.SharedTransport)
+	default:
+		base := newBaseTransport(cfg)
+		doer = createDefaultDoer(cfg, base)
+		transport = base
 	}
 
-	return &Client{
-		doer:    doer,
-		baseURL: cfg.BaseURL,
-		logger:  cfg.Logger,
-		config:  cfg,
-	}, nil
+	var chain http.RoundTripper
+	if hc, ok := doer.(*http.Client); ok {
+		chain = hc.Transport
+	}
+
+	if cfg.CustomDoer != nil && (len(cfg.AllowedHosts) > 0 || len(cfg.BlockedHosts) > 0) {
+		doer = &hostGuardDoer{Next: doer, Allowed: cfg.AllowedHosts, Blocked: cfg.BlockedHosts}
+	}
+
+	if cfg.HedgeAfter > 0 {
+		doer = &hedgingDoer{Next: doer, After: cfg.HedgeAfter, Max: cfg.HedgeMax}
+	}
+
+	c := &Client{
+		doer:          doer,
+		transport:     transport,
+		chain:         chain,
+		baseURL:       cfg.BaseURL,
+		baseURLFunc:   cfg.BaseURLFunc,
+		resolveRef:    cfg.ResolveReference,
+		defaultParams: cfg.DefaultQueryParams,
+		userInfo:      cfg.UserInfo,
+		logger:        cfg.logger(),
+		config:        cfg,
+		health:        cfg.health,
+	}
+
+	if cfg.SingleFlight {
+		c.flight = &singleflight.Group{}
+	}
+
+	return c, nil
 }
 
-// createDefaultDoer builds an http.Client with the configured options.
-func createDefaultDoer(cfg *ClientConfig) Doer {
+// createDefaultDoer builds an http.Client with the configured options on top of the base transport.
+func createDefaultDoer(cfg *ClientConfig, base http.RoundTripper) Doer {
 	client := &http.Client{
 		Timeout:   cfg.Timeout,
-		Transport: buildTransport(cfg),
+		Transport: buildTransport(cfg, base),
 	}
 
 	if cfg.Jar != nil {
 		client.Jar = cfg.Jar
 	}
 
+	if cfg.DisableRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
 	return client
 }
 
+// wrapHTTPClient returns a copy of the user-provided http.Client whose transport is wrapped
+// by the client's transport chain. The original http.Client is left untouched.
+func wrapHTTPClient(cfg *ClientConfig) Doer {
+	client := *cfg.HTTPClient
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = buildTransport(cfg, base)
+
+	return &client
+}
+
+// Transport returns the transport chain requests go through, base transport included, e.g. to
+// inspect its layers in tests or wrap it in another http.Client. With WithHTTPClient, it is the
+// provided client's transport wrapped by the chain.
+//
+// With WithCustomDoer, the chain isn't used: Transport returns the Doer's transport when it is an
+// *http.Client, which is nil when that client uses http.DefaultTransport, and nil for other Doers.
+func (c *Client) Transport() http.RoundTripper {
+	return c.chain
+}
+
+// Close releases the resources held by the client: idle connections of its transport are closed, a
+// logger with a "Flush() error" method is flushed, and a custom Doer implementing io.Closer is closed.
+// With WithHTTPClient, the idle connections of the provided client's transport are closed, which
+// affects every user of that transport.
+//
+// With WithSharedTransport, the shared transport is left untouched.
+//
+// The client is unusable after Close: requests fail with ErrClientClosed. Clients derived with
+// With have their own transport and must be closed separately. Closing twice is a no-op.
+func (c *Client) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+
+	var errs []error
+	if closer, ok := c.config.CustomDoer.(io.Closer); ok {
+		errs = append(errs, closer.Close())
+	}
+	if flusher, ok := c.config.Logger.(interface{ Flush() error }); ok {
+		errs = append(errs, flusher.Flush())
+	}
+
+	return errors.Join(errs...)
+}
+
 func init() {
 	var err error
 	defaultClient, err = New()
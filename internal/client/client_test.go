@@ -1,9 +1,18 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/logger"
 )
 
 func TestClient_ResolveURL(t *testing.T) {
@@ -93,6 +102,41 @@ func TestClient_ResolveURL(t *testing.T) {
 			want:        "https://example.com/api",
 			wantErr:     false,
 		},
+		{
+			name: "default params on relative path",
+			client: &Client{
+				baseURL:       baseURL,
+				defaultParams: url.Values{"api_key": {"secret"}, "format": {"json"}},
+			},
+			pathOrURL: "/users",
+			want:      "https://example.com/api/users?api_key=secret&format=json",
+		},
+		{
+			name: "default params on absolute URL",
+			client: &Client{
+				defaultParams: url.Values{"format": {"json"}},
+			},
+			pathOrURL: "https://other.com/path",
+			want:      "https://other.com/path?format=json",
+		},
+		{
+			name: "request params override defaults",
+			client: &Client{
+				baseURL:       baseURL,
+				defaultParams: url.Values{"format": {"json"}, "page": {"1"}},
+			},
+			pathOrURL:   "/users",
+			queryParams: url.Values{"format": {"xml"}},
+			want:        "https://example.com/api/users?format=xml&page=1",
+		},
+		{
+			name: "URL query overrides defaults",
+			client: &Client{
+				defaultParams: url.Values{"format": {"json"}},
+			},
+			pathOrURL: "https://other.com/path?format=csv",
+			want:      "https://other.com/path?format=csv",
+		},
 		{
 			name: "path starting without slash",
 			client: &Client{
@@ -107,7 +151,7 @@ func TestClient_ResolveURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.client.resolveURL(tt.pathOrURL, tt.queryParams)
+			got, err := tt.client.resolveURL(context.Background(), tt.pathOrURL, tt.queryParams)
 
 			if tt.wantErr {
 				if err == nil {
@@ -222,3 +266,334 @@ func TestClient_AddQueryParams(t *testing.T) {
 		})
 	}
 }
+
+// doerFunc adapts a function to the Doer interface.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestNew_WithHTTPClient(t *testing.T) {
+	var sent *http.Request
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return stringResponse(http.StatusOK, "ok"), nil
+	})
+	hc := &http.Client{Transport: base, Timeout: time.Minute}
+
+	c, err := New(WithHTTPClient(hc), WithHeaders(map[string]string{"X-Api-Key": "abc"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := c.Get(context.Background(), "https://example.com", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sent == nil {
+		t.Fatalf("Expected request to go through the provided transport")
+	}
+	if got := sent.Header.Get("X-Api-Key"); got != "abc" {
+		t.Errorf("X-Api-Key = %q, want %q", got, "abc")
+	}
+	if hc.Transport == nil || c.doer == Doer(hc) {
+		t.Errorf("Expected the provided client to be copied, not modified")
+	}
+	if got := c.doer.(*http.Client).Timeout; got != time.Minute {
+		t.Errorf("Timeout = %s, want the provided client's timeout", got)
+	}
+
+	t.Run("custom doer takes precedence", func(t *testing.T) {
+		doer := doerFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, ""), nil })
+
+		c, err := New(WithHTTPClient(hc), WithCustomDoer(doer))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := c.doer.(doerFunc); !ok {
+			t.Errorf("Expected custom doer to be used, got %T", c.doer)
+		}
+	})
+
+	t.Run("rejects private network blocking", func(t *testing.T) {
+		if _, err := New(WithHTTPClient(hc), WithBlockPrivateNetworks()); err == nil {
+			t.Errorf("Expected an error, private network blocking would be silently dropped")
+		}
+	})
+}
+
+// closingDoer is a Doer implementing io.Closer.
+type closingDoer struct {
+	doerFunc
+	closed bool
+}
+
+func (d *closingDoer) Close() error {
+	d.closed = true
+	return nil
+}
+
+// flushingLogger is a logger with a Flush method.
+type flushingLogger struct {
+	logger.NoOp
+	flushed bool
+}
+
+func (l *flushingLogger) Flush() error {
+	l.flushed = true
+	return nil
+}
+
+func TestClient_Close(t *testing.T) {
+	closedConns := make(chan struct{}, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closedConns <- struct{}{}
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	log := &flushingLogger{}
+	c, err := New(WithBaseURL(srv.URL), WithLogger(log))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "/", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	DrainAndClose(resp)
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case <-closedConns:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the idle connection to be closed")
+	}
+	if !log.flushed {
+		t.Errorf("Expected the logger to be flushed")
+	}
+	if _, err := c.Get(context.Background(), "/", nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected ErrClientClosed after Close, got %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected a second Close to be a no-op, got %v", err)
+	}
+}
+
+func TestClient_Close_CustomDoer(t *testing.T) {
+	doer := &closingDoer{doerFunc: func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, ""), nil }}
+
+	c, _ := New(WithCustomDoer(doer))
+	if err := c.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !doer.closed {
+		t.Errorf("Expected the custom Doer to be closed")
+	}
+}
+
+func TestClient_SharedTransport(t *testing.T) {
+	var newConns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	shared := &http.Transport{}
+	defer shared.CloseIdleConnections()
+
+	parent, err := New(WithBaseURL(srv.URL), WithSharedTransport(shared))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tenant := range []string{"a", "b", "c"} {
+		c, err := parent.With(WithHeaders(map[string]string{"X-Tenant": tenant}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		resp, err := c.Get(context.Background(), "/", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		DrainAndClose(resp)
+
+		if got := resp.Header.Get("X-Tenant"); got != tenant {
+			t.Errorf("Got tenant %q, want %q", got, tenant)
+		}
+		if err := c.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if got := newConns.Load(); got != 1 {
+		t.Errorf("Expected derived clients to share a single connection, got %d", got)
+	}
+
+	if _, err := New(WithSharedTransport(shared), WithBlockPrivateNetworks()); err == nil {
+		t.Errorf("Expected an error combining WithSharedTransport and WithBlockPrivateNetworks")
+	}
+}
+
+func TestClient_Transport(t *testing.T) {
+	custom := roundTripFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, ""), nil })
+
+	t.Run("default doer", func(t *testing.T) {
+		c, _ := New(WithRetryAttempts(2))
+
+		headers, ok := c.Transport().(*headersTransport)
+		if !ok {
+			t.Fatalf("Expected the chain to start with the headers layer, got %T", c.Transport())
+		}
+		if retry, ok := headers.Next.(*retryTransport); !ok || retry.Retries != 2 {
+			t.Errorf("Expected a retry layer with 2 retries next, got %#v", headers.Next)
+		}
+	})
+
+	t.Run("http client", func(t *testing.T) {
+		c, _ := New(WithHTTPClient(&http.Client{Transport: custom}))
+
+		if _, ok := c.Transport().(*headersTransport); !ok {
+			t.Errorf("Expected the provided transport to be wrapped, got %T", c.Transport())
+		}
+	})
+
+	t.Run("custom http client doer", func(t *testing.T) {
+		c, _ := New(WithCustomDoer(&http.Client{Transport: custom}))
+
+		if _, ok := c.Transport().(roundTripFunc); !ok {
+			t.Errorf("Expected the doer's own transport, got %T", c.Transport())
+		}
+	})
+
+	t.Run("custom doer", func(t *testing.T) {
+		c, _ := New(WithCustomDoer(doerFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, ""), nil })))
+
+		if c.Transport() != nil {
+			t.Errorf("Expected no transport, got %T", c.Transport())
+		}
+	})
+}
+
+func TestClient_BaseURLTrailingSlash(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  ClientOption
+		path string
+		want string
+	}{
+		{name: "normalized with slash", opt: WithBaseURL("http://host/api/"), path: "v2", want: "http://host/api/v2"},
+		{name: "normalized without slash", opt: WithBaseURL("http://host/api"), path: "v2", want: "http://host/api/v2"},
+		{name: "normalized absolute path", opt: WithBaseURL("http://host/api/"), path: "/v2", want: "http://host/api/v2"},
+		{name: "preserved with slash", opt: WithBaseURLNoNormalize("http://host/api/"), path: "v2", want: "http://host/api/v2"},
+		{name: "preserved without slash", opt: WithBaseURLNoNormalize("http://host/api"), path: "v2", want: "http://host/v2"},
+		{name: "preserved absolute path", opt: WithBaseURLNoNormalize("http://host/api/"), path: "/v2", want: "http://host/v2"},
+		{name: "preserved dot segments", opt: WithBaseURLNoNormalize("http://host/api/"), path: "../v2", want: "http://host/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opt, WithStrictValidation())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got, err := c.resolveURL(context.Background(), tt.path, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_BaseURLFunc(t *testing.T) {
+	type regionKey struct{}
+	selectErr := errors.New("unknown region")
+	regions := func(ctx context.Context) (*url.URL, error) {
+		switch region, _ := ctx.Value(regionKey{}).(string); region {
+		case "":
+			return nil, nil
+		case "eu", "us":
+			return url.Parse("https://" + region + ".example.com/api")
+		case "relative":
+			return &url.URL{Path: "/api"}, nil
+		default:
+			return nil, selectErr
+		}
+	}
+
+	c, err := New(WithBaseURL("https://example.com"), WithBaseURLFunc(regions))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		region  string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "selected", region: "eu", path: "items", want: "https://eu.example.com/api/items"},
+		{name: "another selected", region: "us", path: "/items", want: "https://us.example.com/api/items"},
+		{name: "static fallback", path: "items", want: "https://example.com/items"},
+		{name: "absolute URL", region: "eu", path: "https://other.com/x", want: "https://other.com/x"},
+		{name: "selection error", region: "mars", path: "items", wantErr: true},
+		{name: "relative selection", region: "relative", path: "items", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), regionKey{}, tt.region)
+
+			got, err := c.resolveURL(ctx, tt.path, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("selection error aborts the request", func(t *testing.T) {
+		called := false
+		c, _ := New(
+			WithCustomDoer(doerFunc(func(*http.Request) (*http.Response, error) {
+				called = true
+				return stringResponse(http.StatusOK, ""), nil
+			})),
+			WithBaseURLFunc(regions),
+		)
+
+		ctx := context.WithValue(context.Background(), regionKey{}, "mars")
+		if _, err := c.Get(ctx, "items", nil); !errors.Is(err, selectErr) {
+			t.Errorf("Expected the selection error, got %v", err)
+		}
+		if called {
+			t.Errorf("Expected no request to be sent")
+		}
+	})
+}
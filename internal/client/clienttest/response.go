@@ -0,0 +1,62 @@
+// Package clienttest provides helpers for testing code built on the client package, such as
+// ready-made responses to return from a mock Doer:
+//
+//	c, _ := client.New(client.WithCustomDoer(&mockDoer{
+//		response: clienttest.NewJSONResponse(http.StatusOK, map[string]bool{"ok": true}),
+//	}))
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// NewStringResponse returns a response with the given status and a text/plain body.
+func NewStringResponse(status int, body string) *http.Response {
+	return NewResponse(status, "text/plain; charset=utf-8", []byte(body))
+}
+
+// NewJSONResponse returns a response with the given status and v encoded as its JSON body.
+// Like httptest.NewRequest, it panics if v can't be encoded, which is a bug in the test.
+func NewJSONResponse(status int, v any) *http.Response {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("clienttest: failed to encode JSON response: %v", err))
+	}
+	return NewResponse(status, "application/json", body)
+}
+
+// NewResponse returns a fully formed HTTP/1.1 response with the given status, content type and body.
+// Its Content-Length is set, and its body can be closed any number of times and rewound with
+// io.Seeker to be read again.
+func NewResponse(status int, contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          &responseBody{Reader: bytes.NewReader(body)},
+		ContentLength: int64(len(body)),
+	}
+}
+
+// responseBody is an in-memory response body, kept seekable so tests can read it more than once.
+type responseBody struct {
+	*bytes.Reader
+}
+
+// Close implements io.Closer. It is a no-op.
+func (b *responseBody) Close() error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package clienttest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNewJSONResponse(t *testing.T) {
+	resp := NewJSONResponse(http.StatusCreated, map[string]int{"id": 42})
+
+	if resp.StatusCode != http.StatusCreated || resp.Status != "201 Created" {
+		t.Errorf("Got status %q, want %q", resp.Status, "201 Created")
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Got Content-Type %q, want %q", got, "application/json")
+	}
+	if resp.ContentLength != 9 || resp.Header.Get("Content-Length") != "9" {
+		t.Errorf("Got Content-Length %d, want 9", resp.ContentLength)
+	}
+
+	for range 2 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(body) != `{"id":42}` {
+			t.Errorf("Got body %q, want %q", body, `{"id":42}`)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Body.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestNewStringResponse(t *testing.T) {
+	resp := NewStringResponse(http.StatusNotFound, "missing")
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Got Content-Type %q", got)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "missing" {
+		t.Errorf("Got body %q, want %q", body, "missing")
+	}
+}
+
+func TestNewJSONResponse_Unencodable(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+	NewJSONResponse(http.StatusOK, make(chan int))
+}
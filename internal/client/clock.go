@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time used by the client for retries and backoff, so that tests can
+// replace real waits with a fake clock. See WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep waits for d, returning early with the context error if ctx is done first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+// Now implements the Clock interface.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements the Clock interface.
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/glwbr/brisa/pkg/errors"
+)
+
+// Decoder decodes response bodies of a given media type, e.g. to plug XML or msgpack support in.
+// See WithDecoder and WithDefaultDecoder.
+type Decoder interface {
+	// Decode reads r and stores the result in v.
+	Decode(r io.Reader, v any) error
+
+	// ContentType returns the media type the decoder handles, e.g. "application/json".
+	ContentType() string
+}
+
+// JSONDecoder decodes JSON bodies. Like DecodeJSON, it reports malformed JSON and type mismatches as
+// a *JSONDecodeError.
+type JSONDecoder struct{}
+
+// Decode implements the Decoder interface.
+func (JSONDecoder) Decode(r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return decodeError(body, err)
+	}
+
+	return nil
+}
+
+// ContentType implements the Decoder interface.
+func (JSONDecoder) ContentType() string { return "application/json" }
+
+// XMLDecoder decodes XML bodies with encoding/xml.
+type XMLDecoder struct{}
+
+// Decode implements the Decoder interface.
+func (XMLDecoder) Decode(r io.Reader, v any) error {
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to decode XML")
+	}
+	return nil
+}
+
+// ContentType implements the Decoder interface.
+func (XMLDecoder) ContentType() string { return "application/xml" }
+
+// GetDecoded sends a GET request and decodes the response body into v, with the decoder registered
+// for the response Content-Type (see WithDecoder), or the default decoder when none matches or the
+// response has no Content-Type. Unless opts sets one, the Accept header lists the media types of the
+// default decoder, preferred, and of the registered ones.
+// Error statuses are returned as errors, like Get does, without decoding the body.
+func (c *Client) GetDecoded(ctx context.Context, path string, opts *RequestConfig, v any) error {
+	if opts == nil || !hasHeader(opts.Headers, "Accept") {
+		opts = withRequestHeader(opts, "Accept", c.config.accept())
+	}
+
+	resp, err := c.Get(ctx, path, opts)
+	if err != nil {
+		DrainAndClose(resp)
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.config.decoder(resp.Header.Get("Content-Type")).Decode(resp.Body, v)
+}
+
+// defaultDecoder returns the decoder used when no registered one matches, JSONDecoder unless set.
+func (cfg *ClientConfig) defaultDecoder() Decoder {
+	if cfg.DefaultDecoder != nil {
+		return cfg.DefaultDecoder
+	}
+	return JSONDecoder{}
+}
+
+// decoder returns the decoder for a response with the given Content-Type.
+func (cfg *ClientConfig) decoder(contentType string) Decoder {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if dec, ok := cfg.Decoders[mediaType]; ok {
+			return dec
+		}
+	}
+	return cfg.defaultDecoder()
+}
+
+// accept returns the Accept header value listing the media types that can be decoded.
+func (cfg *ClientConfig) accept() string {
+	def := cfg.defaultDecoder().ContentType()
+
+	types := []string{def}
+	for _, mediaType := range slices.Sorted(maps.Keys(cfg.Decoders)) {
+		if mediaType != def {
+			types = append(types, mediaType)
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// Encoder encodes request bodies in a given media type. See WithDefaultEncoder and Send.
+type Encoder interface {
+	// Encode writes v, encoded, to w.
+	Encode(w io.Writer, v any) error
+
+	// ContentType returns the media type of the encoded bodies, set as the request Content-Type.
+	ContentType() string
+}
+
+// JSONEncoder encodes request bodies as JSON.
+type JSONEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (JSONEncoder) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// ContentType implements the Encoder interface.
+func (JSONEncoder) ContentType() string { return "application/json" }
+
+// XMLEncoder encodes request bodies as XML with encoding/xml.
+type XMLEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (XMLEncoder) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// ContentType implements the Encoder interface.
+func (XMLEncoder) ContentType() string { return "application/xml" }
+
+// FormEncoder encodes request bodies as URL-encoded forms. The value must be a url.Values,
+// a map[string][]string or a map[string]string.
+type FormEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (FormEncoder) Encode(w io.Writer, v any) error {
+	var form url.Values
+	switch v := v.(type) {
+	case url.Values:
+		form = v
+	case map[string][]string:
+		form = v
+	case map[string]string:
+		form = make(url.Values, len(v))
+		for k, value := range v {
+			form.Set(k, value)
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as a form", v)
+	}
+
+	_, err := io.WriteString(w, form.Encode())
+	return err
+}
+
+// ContentType implements the Encoder interface.
+func (FormEncoder) ContentType() string { return "application/x-www-form-urlencoded" }
+
+// Send sends a request with the given method whose body is v, encoded with RequestConfig.Encoder, or
+// the default encoder (see WithDefaultEncoder) when opts sets none, and returns the response as is.
+// Content-Type is set to the encoder's media type unless opts sets it.
+//
+// v takes precedence over the body of opts: when v is not nil, its encoding replaces any Body,
+// BodyBytes or GetBody set in opts. When v is nil, the body of opts, if any, is sent unchanged and
+// neither the encoder nor Content-Type are involved, making Send usable for any method.
+// The encoded body is held in memory, so it can be replayed for retries and redirects.
+func (c *Client) Send(ctx context.Context, method, path string, v any, opts *RequestConfig) (*http.Response, error) {
+	if v == nil {
+		return c.do(ctx, method, path, opts)
+	}
+
+	enc := c.config.defaultEncoder()
+	if opts != nil && opts.Encoder != nil {
+		enc = opts.Encoder
+	}
+
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, v); err != nil {
+		return nil, errors.Wrap(err, "failed to encode request body")
+	}
+
+	// Work on a copy, leaving the caller's config untouched.
+	cfg := RequestConfig{}
+	if opts != nil {
+		cfg = *opts
+	}
+	cfg.Body, cfg.BodyBytes, cfg.GetBody = nil, buf.Bytes(), nil
+	opts = &cfg
+
+	if !hasHeader(opts.Headers, "Content-Type") {
+		opts = withRequestHeader(opts, "Content-Type", enc.ContentType())
+	}
+
+	return c.do(ctx, method, path, opts)
+}
+
+// defaultEncoder returns the encoder used by Send, JSONEncoder unless set.
+func (cfg *ClientConfig) defaultEncoder() Encoder {
+	if cfg.DefaultEncoder != nil {
+		return cfg.DefaultEncoder
+	}
+	return JSONEncoder{}
+}
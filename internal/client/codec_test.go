@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_GetDecoded(t *testing.T) {
+	type item struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	var gotAccept string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		io.WriteString(w, `{"name":"json"}`)
+	})
+	mux.HandleFunc("/xml", func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, `<item><name>xml</name></item>`)
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		w.Header()["Content-Type"] = nil
+		io.WriteString(w, `<item><name>untyped</name></item>`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		opts       []ClientOption
+		path       string
+		want       string
+		wantAccept string
+		wantErr    bool
+	}{
+		{name: "json by default", path: "/json", want: "json", wantAccept: "application/json"},
+		{name: "xml without registry", path: "/xml", wantAccept: "application/json", wantErr: true},
+		{
+			name:       "xml from registry",
+			opts:       []ClientOption{WithDecoder(XMLDecoder{})},
+			path:       "/xml",
+			want:       "xml",
+			wantAccept: "application/json, application/xml",
+		},
+		{
+			name:       "json alongside registry",
+			opts:       []ClientOption{WithDecoder(XMLDecoder{})},
+			path:       "/json",
+			want:       "json",
+			wantAccept: "application/json, application/xml",
+		},
+		{
+			name:       "default decoder for untyped responses",
+			opts:       []ClientOption{WithDefaultDecoder(XMLDecoder{}), WithDecoder(JSONDecoder{})},
+			path:       "/untyped",
+			want:       "untyped",
+			wantAccept: "application/xml, application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(append(tt.opts, WithBaseURL(srv.URL))...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got item
+			err = c.GetDecoded(context.Background(), tt.path, nil, &got)
+			if gotAccept != tt.wantAccept {
+				t.Errorf("Got Accept %q, want %q", gotAccept, tt.wantAccept)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected a decoding error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Got %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	type item struct {
+		XMLName xml.Name `json:"-" xml:"item"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	var gotMethod, gotType, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotType, gotBody = r.Method, r.Header.Get("Content-Type"), string(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		opts     []ClientOption
+		v        any
+		reqOpts  *RequestConfig
+		wantType string
+		wantBody string
+	}{
+		{name: "json by default", v: item{Name: "a"}, wantType: "application/json", wantBody: "{\"name\":\"a\"}\n"},
+		{
+			name:     "xml",
+			opts:     []ClientOption{WithDefaultEncoder(XMLEncoder{})},
+			v:        item{Name: "a"},
+			wantType: "application/xml",
+			wantBody: "<item><name>a</name></item>",
+		},
+		{
+			name:     "form",
+			opts:     []ClientOption{WithDefaultEncoder(FormEncoder{})},
+			v:        map[string]string{"name": "a b"},
+			wantType: "application/x-www-form-urlencoded",
+			wantBody: "name=a+b",
+		},
+		{
+			name:     "per-request encoder",
+			opts:     []ClientOption{WithDefaultEncoder(XMLEncoder{})},
+			v:        map[string]string{"name": "a b"},
+			reqOpts:  &RequestConfig{Encoder: FormEncoder{}},
+			wantType: "application/x-www-form-urlencoded",
+			wantBody: "name=a+b",
+		},
+		{
+			name:     "value replaces body",
+			v:        item{Name: "a"},
+			reqOpts:  &RequestConfig{Body: strings.NewReader("ignored"), Headers: map[string]string{"Content-Type": "application/vnd.api+json"}},
+			wantType: "application/vnd.api+json",
+			wantBody: "{\"name\":\"a\"}\n",
+		},
+		{
+			name:     "nil value keeps body",
+			reqOpts:  &RequestConfig{Body: strings.NewReader("raw")},
+			wantBody: "raw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Send(context.Background(), http.MethodPut, srv.URL, tt.v, tt.reqOpts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			DrainAndClose(resp)
+
+			if gotMethod != http.MethodPut {
+				t.Errorf("Got method %q, want %q", gotMethod, http.MethodPut)
+			}
+			if gotType != tt.wantType {
+				t.Errorf("Got Content-Type %q, want %q", gotType, tt.wantType)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("Got body %q, want %q", gotBody, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("unsupported form value", func(t *testing.T) {
+		c, err := New(WithDefaultEncoder(FormEncoder{}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := c.Send(context.Background(), http.MethodPost, srv.URL, item{}, nil); err == nil {
+			t.Errorf("Expected an encoding error")
+		}
+	})
+}
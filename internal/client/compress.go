@@ -0,0 +1,128 @@
+package client
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// decompressor returns a reader decoding r, compressed with a given content coding.
+type decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// contentCoding is a content coding decoded with WithAutoDecompress.
+type contentCoding struct {
+	name       string
+	decompress decompressor
+}
+
+// contentCodings lists the content codings decoded with WithAutoDecompress, in order of preference.
+// Codings backed by optional dependencies are registered by the files built with their tag.
+var contentCodings = []contentCoding{
+	{"br", func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(brotli.NewReader(r)), nil }},
+	{"gzip", func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }},
+	// The "deflate" coding is the zlib format, not raw deflate (RFC 9110, section 8.4.1.2).
+	{"deflate", zlib.NewReader},
+}
+
+// registerContentCoding adds a content coding decoded with WithAutoDecompress, preferred over the
+// ones registered before. It is meant to be called from init functions.
+func registerContentCoding(name string, decompress decompressor) {
+	contentCodings = append([]contentCoding{{name, decompress}}, contentCodings...)
+}
+
+// acceptEncoding returns the Accept-Encoding header value advertising the supported codings.
+func acceptEncoding() string {
+	names := make([]string, len(contentCodings))
+	for i, coding := range contentCodings {
+		names[i] = coding.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// findDecompressor returns the decompressor of the given Content-Encoding, or nil if unsupported.
+func findDecompressor(contentEncoding string) decompressor {
+	contentEncoding = strings.ToLower(strings.TrimSpace(contentEncoding))
+	for _, coding := range contentCodings {
+		if coding.name == contentEncoding {
+			return coding.decompress
+		}
+	}
+	return nil
+}
+
+// decompressTransport negotiates the content codings of responses and decodes them, see
+// WithAutoDecompress.
+type decompressTransport struct {
+	Next http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+// Requests carrying their own Accept-Encoding are left alone, their responses being returned as is.
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return t.next().RoundTrip(req)
+	}
+
+	// Work on a copy, so that a retried request is negotiated again rather than left alone.
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", acceptEncoding())
+
+	resp, err := t.next().RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		return resp, err
+	}
+
+	decompress := findDecompressor(resp.Header.Get("Content-Encoding"))
+	if decompress == nil {
+		return resp, nil
+	}
+
+	resp.Body = &decompressedBody{body: resp.Body, decompress: decompress}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return resp, nil
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *decompressTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// decompressedBody decodes a compressed response body. The decoder is set up on the first read, so
+// that empty bodies, e.g. of HEAD requests, are never decoded.
+type decompressedBody struct {
+	body       io.ReadCloser
+	decompress decompressor
+
+	r   io.ReadCloser
+	err error
+}
+
+// Read implements the io.Reader interface.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = b.decompress(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+// Close implements the io.Closer interface.
+func (b *decompressedBody) Close() error {
+	if b.r != nil {
+		b.r.Close()
+	}
+	return b.body.Close()
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressedHandler serves body encoded with the coding named by the "coding" query parameter,
+// recording the Accept-Encoding of the request.
+func compressedHandler(t *testing.T, body string, encode map[string]func(io.Writer) io.WriteCloser, gotAccept *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotAccept = r.Header.Get("Accept-Encoding")
+
+		coding := r.URL.Query().Get("coding")
+		newWriter, ok := encode[coding]
+		if !ok {
+			io.WriteString(w, body)
+			return
+		}
+
+		var buf bytes.Buffer
+		zw := newWriter(&buf)
+		if _, err := io.WriteString(zw, body); err != nil {
+			t.Errorf("Failed to encode fixture: %v", err)
+		}
+		zw.Close()
+
+		w.Header().Set("Content-Encoding", coding)
+		if r.Method != http.MethodHead {
+			w.Write(buf.Bytes())
+		}
+	})
+}
+
+func TestClient_AutoDecompress(t *testing.T) {
+	body := strings.Repeat("compressible ", 100)
+
+	var gotAccept string
+	srv := httptest.NewServer(compressedHandler(t, body, map[string]func(io.Writer) io.WriteCloser{
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+	}, &gotAccept))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		coding     string
+		headers    map[string]string
+		wantAccept string
+		wantBody   string
+		wantCoding string
+	}{
+		{name: "brotli", coding: "br", wantAccept: acceptEncoding(), wantBody: body},
+		{name: "gzip", coding: "gzip", wantAccept: acceptEncoding(), wantBody: body},
+		{name: "deflate", coding: "deflate", wantAccept: acceptEncoding(), wantBody: body},
+		{name: "identity", wantAccept: acceptEncoding(), wantBody: body},
+		{
+			name:       "caller negotiated",
+			coding:     "gzip",
+			headers:    map[string]string{"Accept-Encoding": "gzip"},
+			wantAccept: "gzip",
+			wantCoding: "gzip",
+		},
+	}
+
+	c, err := New(WithBaseURL(srv.URL), WithAutoDecompress())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.Get(context.Background(), "/", &RequestConfig{Params: url.Values{"coding": {tt.coding}}, Headers: tt.headers})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if gotAccept != tt.wantAccept {
+				t.Errorf("Got Accept-Encoding %q, want %q", gotAccept, tt.wantAccept)
+			}
+			if coding := resp.Header.Get("Content-Encoding"); coding != tt.wantCoding {
+				t.Errorf("Got Content-Encoding %q, want %q", coding, tt.wantCoding)
+			}
+			if tt.wantBody != "" && string(got) != tt.wantBody {
+				t.Errorf("Got a %d byte body, want the %d byte decoded one", len(got), len(tt.wantBody))
+			}
+		})
+	}
+
+	t.Run("head", func(t *testing.T) {
+		resp, err := c.Head(context.Background(), "/", &RequestConfig{Params: url.Values{"coding": {"gzip"}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || len(got) != 0 {
+			t.Errorf("Got body %q and error %v, want an empty body", got, err)
+		}
+	})
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	got := acceptEncoding()
+	for _, coding := range []string{"br", "gzip", "deflate"} {
+		if !strings.Contains(got, coding) {
+			t.Errorf("Got %q, want %s advertised", got, coding)
+		}
+	}
+	if findDecompressor(" GZIP ") == nil {
+		t.Errorf("Expected content codings to be matched case-insensitively")
+	}
+	if findDecompressor("compress") != nil {
+		t.Errorf("Expected unsupported codings to have no decompressor")
+	}
+}
@@ -1,10 +1,16 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"maps"
+	"mime"
+	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,19 +20,144 @@ import (
 // ClientConfig defines the configuration options for the HTTP client.
 // All fields are optional, with sensible defaults provided by buildConfig.
 type ClientConfig struct {
-	BaseURL       *url.URL
-	Timeout       time.Duration
-	RetryAttempts int
+	BaseURL              *url.URL
+	BaseURLFunc          func(ctx context.Context) (*url.URL, error)
+	ResolveReference     bool
+	UserInfo             *url.Userinfo
+	Timeout              time.Duration
+	ContextTimeout       time.Duration
+	MinRequestTimeout    time.Duration
+	RetryAttempts        int
+	RetryOn              func(resp *http.Response, err error) bool
+	RetryMethods         []string
+	IdempotencyKeyHeader string
+	HedgeAfter           time.Duration
+	HedgeMax             int
 
-	Headers map[string]string
-	Jar     *cookiejar.Jar
+	DialTimeout            time.Duration
+	KeepAlive              time.Duration
+	IdleConnTimeout        time.Duration
+	DNSCacheTTL            time.Duration
+	TLSHandshakeTimeout    time.Duration
+	ResponseHeaderTimeout  time.Duration
+	MaxResponseHeaderBytes int64
+	MaxResponseHeaders     int
+	ExpectContinueTimeout  time.Duration
+	ExpectContinue         bool
+	DisableKeepAlives      bool
+	ForceHTTP2             bool
+	ForceHTTP11            bool
+	UnixSocket             string
+	Proxies                []*url.URL
+	ProxyRotation          RotationStrategy
 
-	Logger logger.Logger
-	Debug  bool
+	Headers            map[string]string
+	DynamicUserAgent   func(ctx context.Context) string
+	DefaultQueryParams url.Values
+	HealthPath         string
+	Jar                *cookiejar.Jar
+	CookieJarEnabled   bool
 
-	CustomDoer Doer
+	MaxResponseSize  int64
+	MaxRequestSize   int64
+	BufferBodySize   int64
+	ForceChunked     bool
+	AutoDrainOnError bool
+	AutoContentType  bool
+	SingleFlight     bool
+	CacheKeyIgnore   []string
+	StatusValidator  func(status int) bool
+	ErrorDecoder     func(body []byte) (any, error)
+	Decoders         map[string]Decoder
+	DefaultDecoder   Decoder
+	DefaultEncoder   Encoder
+
+	AutoDecompress    bool
+	DisableRedirects  bool
+	RedirectsAsErrors bool
+	FollowCreated     bool
+
+	Logger         logger.Logger
+	LoggerFields   map[string]any
+	Debug          bool
+	DebugDump      bool
+	PrettyJSONLogs bool
+	ConnTrace      bool
+	LogResolvedURL bool
+	Metrics        MetricsRecorder
+	Clock          Clock
+
+	SlowRequestThreshold time.Duration
+	AdaptiveRateLimit    bool
+	HealthWindow         int
+
+	CustomDoer       Doer
+	HTTPClient       *http.Client
+	SharedTransport  *http.Transport
+	Interceptors     []RequestInterceptor
+	BodyInterceptors []BodyInterceptor
+	BodyValidators   []BodyValidator
+	Transforms       []ResponseTransform
+	CaptureOnError   CaptureFunc
+
+	Middlewares map[TransportLayer][]TransportMiddleware
+
+	AllowedHosts         []string
+	BlockedHosts         []string
+	BlockPrivateNetworks bool
+
+	// StrictValidation makes New fail when any option received invalid input.
+	StrictValidation bool
+
+	// RequireBaseURL makes New fail when no valid base URL was configured.
+	RequireBaseURL bool
+
+	errs []error
+
+	// health tracks host health for the transport chain, created by newClient when HealthWindow is set.
+	health *hostHealth
 }
 
+// RequestInterceptor inspects or modifies a fully built request right before it is sent.
+// It receives the context passed to the request method, making it suitable for work such as
+// signing the request. Returning an error aborts the request.
+type RequestInterceptor func(ctx context.Context, req *http.Request) error
+
+// BodyInterceptor wraps a response body, e.g. to verify a checksum or count lines as it is read.
+// The returned ReadCloser must close the body it wraps when closed.
+type BodyInterceptor func(body io.ReadCloser) io.ReadCloser
+
+// BodyValidator inspects the buffered body of a successful response and returns an error to reject it,
+// e.g. for APIs answering 200 with an error object.
+type BodyValidator func(resp *http.Response, body []byte) error
+
+// ResponseTransform can replace or modify a response before it is checked, e.g. to remap the status
+// of an API answering 200 for missing resources. See WithResponseTransform.
+type ResponseTransform func(resp *http.Response) (*http.Response, error)
+
+// TransportMiddleware wraps the next RoundTripper of the transport chain, e.g. for tracing or caching.
+type TransportMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// TransportLayer is an insertion point for middleware in the transport chain. From the outside in,
+// the chain is: Outermost middleware, default headers, BeforeRetry middleware, retries, adaptive rate
+// limiting (see WithAdaptiveRateLimit), AfterRetry middleware, metrics, host health, logging, host
+// guard, decompression and finally the base transport.
+type TransportLayer int
+
+const (
+	// Outermost middleware wraps the whole chain and sees requests as sent by the client, before
+	// default headers are added.
+	Outermost TransportLayer = iota
+
+	// BeforeRetry middleware sees each request once, whatever the number of attempts, so a round
+	// trip measured there includes retries and their backoff.
+	BeforeRetry
+
+	// AfterRetry middleware sees every attempt separately, once the adaptive rate limiter let it through,
+	// right before metrics and logging.
+	AfterRetry
+)
+
 // ClientOption defines a function that modifies the Config object.
 // It is used to apply flexible and composable configuration settings.
 type ClientOption func(*ClientConfig)
@@ -34,11 +165,312 @@ type ClientOption func(*ClientConfig)
 // WithTimeout sets the maximum duration for HTTP requests.
 // The timeout includes connection time, any redirects, and reading the response body.
 // A timeout <= 0 will be ignored and the default timeout will be used.
+//
+// It is the same as WithClientTimeout: it sets http.Client.Timeout, so it doesn't apply with
+// WithCustomDoer, and with WithHTTPClient the provided client's Timeout is used instead.
+// Use WithContextTimeout for a timeout that applies whatever the Doer.
 func WithTimeout(d time.Duration) ClientOption {
+	return WithClientTimeout(d)
+}
+
+// WithClientTimeout sets http.Client.Timeout of the client's own http.Client, which bounds connection
+// time, redirects and reading the response body. It only applies to the default Doer: it is ignored
+// with WithCustomDoer and WithHTTPClient. The default is 10 seconds. A timeout <= 0 is ignored.
+func WithClientTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid timeout %s: must be positive", d))
+			return
+		}
+		cfg.Timeout = d
+	}
+}
+
+// WithContextTimeout bounds every request with a context deadline d from when it is made, on top of
+// any deadline of the caller's context. Unlike WithClientTimeout, it works with every Doer, custom
+// ones included, and a request exceeding it fails with ErrTimeout. The deadline covers reading the
+// response body, and is released when the body is closed. A timeout <= 0 is ignored.
+func WithContextTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid context timeout %s: must be positive", d))
+			return
+		}
+		cfg.ContextTimeout = d
+	}
+}
+
+// WithTotalTimeout bounds a whole call, every retry and backoff included, with a single deadline d
+// from when the request is made. It is the same as WithContextTimeout, named for use alongside
+// WithRetryAttempts: once the deadline is close, no further attempt is started, and an attempt in
+// flight when it passes is aborted with ErrTimeout.
+//
+// It coexists with the per-attempt timeouts of the transport, such as WithResponseHeaderTimeout,
+// which bound each attempt separately, the first one to expire winning.
+func WithTotalTimeout(d time.Duration) ClientOption {
+	return WithContextTimeout(d)
+}
+
+// WithNoTimeout removes the overall request timeout, so a response body can be read for as long as needed.
+// This is meant for streaming responses; combine it with WithDialTimeout and WithResponseHeaderTimeout
+// so that connecting and waiting for the server still fail fast, and with a context to stop the stream.
+func WithNoTimeout() ClientOption {
+	return func(cfg *ClientConfig) { cfg.Timeout = 0 }
+}
+
+// WithMinRequestTimeout is a safety net making sure every request has a deadline: a request whose
+// context has none gets one d from now, kept until its response body is closed. A context deadline
+// closer than d is left as is, but logged as a warning since it is likely a mistake.
+//
+// This matters when WithTimeout doesn't apply, e.g. with WithNoTimeout or WithCustomDoer: a request
+// made with context.Background() against a server that never answers would otherwise hang forever.
+// It is disabled by default.
+func WithMinRequestTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid minimum request timeout %s: must be positive", d))
+			return
+		}
+		cfg.MinRequestTimeout = d
+	}
+}
+
+// WithDialTimeout sets the maximum time spent establishing the TCP connection.
+// A duration <= 0 will be ignored and the default of 30s will be used.
+//
+// Connection-level timeouts are enforced by the transport, independently of the overall
+// timeout set by WithTimeout (http.Client.Timeout), which bounds the whole exchange including
+// reading the body. Whichever expires first aborts the request.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid dial timeout %s: must be positive", d))
+			return
+		}
+		cfg.DialTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout sets the maximum time to wait for the TLS handshake.
+// A duration <= 0 will be ignored and the default of 10s will be used.
+// See WithDialTimeout for how it interacts with the overall timeout.
+func WithTLSHandshakeTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid TLS handshake timeout %s: must be positive", d))
+			return
+		}
+		cfg.TLSHandshakeTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout sets the maximum time to wait for the response headers after the
+// request has been written. It does not include the time to read the body, which makes it a good
+// fit for streaming responses. A duration <= 0 will be ignored and no header timeout is applied.
+// See WithDialTimeout for how it interacts with the overall timeout.
+func WithResponseHeaderTimeout(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid response header timeout %s: must be positive", d))
+			return
+		}
+		cfg.ResponseHeaderTimeout = d
+	}
+}
+
+// TransportTimeouts groups the timeouts of the client's base transport, see WithTransportTimeouts.
+// A zero field keeps the default, as returned by DefaultTransportTimeouts.
+type TransportTimeouts struct {
+	// DialTimeout bounds establishing the TCP connection.
+	DialTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes on open connections.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ExpectContinueTimeout bounds the wait for "100 Continue" when sending "Expect: 100-continue".
+	ExpectContinueTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for the response headers once the request is written.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout is how long an idle connection is kept in the pool before being closed.
+	IdleConnTimeout time.Duration
+}
+
+// DefaultTransportTimeouts returns the timeouts the base transport uses unless configured, those of
+// http.DefaultTransport. There is no response header timeout by default.
+func DefaultTransportTimeouts() TransportTimeouts {
+	return TransportTimeouts{
+		DialTimeout:           defaultDialTimeout,
+		KeepAlive:             defaultKeepAlive,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: defaultExpectContinueTimeout,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+	}
+}
+
+// WithTransportTimeouts sets the timeouts of the base transport at once, e.g. starting from
+// DefaultTransportTimeouts. Zero fields keep their current value, so it combines with the single
+// timeout options such as WithDialTimeout; a negative one is invalid and the whole group is ignored.
+//
+// These timeouts bound a step of each attempt, independently of the overall timeout set by
+// WithTimeout or WithTotalTimeout: whichever expires first aborts the request. They only apply to the
+// client's own transport, so they have no effect with WithHTTPClient or WithCustomDoer.
+func WithTransportTimeouts(t TransportTimeouts) ClientOption {
+	return func(cfg *ClientConfig) {
+		for _, timeout := range []struct {
+			name string
+			d    time.Duration
+		}{
+			{"dial", t.DialTimeout},
+			{"keep-alive", t.KeepAlive},
+			{"TLS handshake", t.TLSHandshakeTimeout},
+			{"expect continue", t.ExpectContinueTimeout},
+			{"response header", t.ResponseHeaderTimeout},
+			{"idle connection", t.IdleConnTimeout},
+		} {
+			if timeout.d < 0 {
+				cfg.addError(fmt.Errorf("invalid %s timeout %s: must not be negative", timeout.name, timeout.d))
+				return
+			}
+		}
+
+		setIfPositive := func(dst *time.Duration, d time.Duration) {
+			if d > 0 {
+				*dst = d
+			}
+		}
+		setIfPositive(&cfg.DialTimeout, t.DialTimeout)
+		setIfPositive(&cfg.KeepAlive, t.KeepAlive)
+		setIfPositive(&cfg.TLSHandshakeTimeout, t.TLSHandshakeTimeout)
+		setIfPositive(&cfg.ExpectContinueTimeout, t.ExpectContinueTimeout)
+		setIfPositive(&cfg.ResponseHeaderTimeout, t.ResponseHeaderTimeout)
+		setIfPositive(&cfg.IdleConnTimeout, t.IdleConnTimeout)
+	}
+}
+
+// WithMaxResponseHeaderBytes limits the size of the response headers the base transport accepts to
+// n bytes, instead of net/http's default of 1MB: a server sending more makes the request fail. It is a
+// hardening option for clients fetching untrusted URLs, see also WithMaxResponseHeaders. It only
+// applies to the client's own transport, so it has no effect with WithHTTPClient or WithCustomDoer.
+func WithMaxResponseHeaderBytes(n int) ClientOption {
+	return func(cfg *ClientConfig) {
+		if n <= 0 {
+			cfg.addError(fmt.Errorf("invalid max response header bytes %d: must be positive", n))
+			return
+		}
+		cfg.MaxResponseHeaderBytes = int64(n)
+	}
+}
+
+// WithMaxResponseHeaders rejects responses carrying more than n header fields, counting each value of
+// repeated headers, with ErrTooManyHeaders. Unlike WithMaxResponseHeaderBytes, it applies whatever
+// the Doer, once the response is received.
+func WithMaxResponseHeaders(n int) ClientOption {
+	return func(cfg *ClientConfig) {
+		if n <= 0 {
+			cfg.addError(fmt.Errorf("invalid max response headers %d: must be positive", n))
+			return
+		}
+		cfg.MaxResponseHeaders = n
+	}
+}
+
+// WithExpectContinue sends "Expect: 100-continue" on every request with a body, so a server can reject
+// it (e.g. failed auth, body too large) before the body is transmitted, saving bandwidth on large uploads.
+// The body is sent once the server answers "100 Continue", or after waiting for timeout without a reply.
+// A timeout <= 0 keeps the default of 1s. To enable it for a single request, use RequestConfig.ExpectContinue.
+func WithExpectContinue(timeout time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.ExpectContinue = true
+		if timeout > 0 {
+			cfg.ExpectContinueTimeout = timeout
+		}
+	}
+}
+
+// WithDisableKeepAlives controls whether connections are closed after each request instead of being
+// reused. Disabling keep-alives forces a new TCP (and TLS) handshake per request, which adds latency
+// and load on both ends; it is mostly useful for load testing or when talking to servers that
+// mishandle persistent connections.
+func WithDisableKeepAlives(disable bool) ClientOption {
+	return func(cfg *ClientConfig) { cfg.DisableKeepAlives = disable }
+}
+
+// WithForceHTTP2 makes sure the transport attempts HTTP/2. This is already the default: the base
+// transport is cloned from http.DefaultTransport, which attempts HTTP/2 even with the custom dialer
+// set up by this package. The option only makes the choice explicit, e.g. to override a previous
+// WithForceHTTP11. HTTP/2 is negotiated through TLS ALPN, so plain-text URLs and servers without
+// HTTP/2 support keep using HTTP/1.1.
+func WithForceHTTP2() ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.ForceHTTP2 = true
+		cfg.ForceHTTP11 = false
+	}
+}
+
+// WithForceHTTP11 disables HTTP/2 so every request uses HTTP/1.1, which is useful against servers or
+// proxies with broken HTTP/2 implementations. It overrides a previous WithForceHTTP2.
+func WithForceHTTP11() ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.ForceHTTP11 = true
+		cfg.ForceHTTP2 = false
+	}
+}
+
+// WithUnixSocket sends every request over the unix domain socket at path, whatever the URL host, e.g.
+// to talk to Docker's API over /var/run/docker.sock. The URL host is then only a placeholder, still
+// sent as the Host header, as in WithBaseURL("http://unix") followed by Get(ctx, "/v1.40/containers/json", nil).
+//
+// Proxies are not used, and New returns an error with WithBlockPrivateNetworks. It only applies to
+// the client's own transport, so it has no effect with WithHTTPClient, WithCustomDoer or WithSharedTransport.
+func WithUnixSocket(path string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if path == "" {
+			cfg.addError(fmt.Errorf("invalid unix socket: empty path"))
+			return
+		}
+		cfg.UnixSocket = path
+	}
+}
+
+// WithDNSCache caches the addresses host names resolve to for ttl, saving a DNS lookup on every new
+// connection to hosts the client talks to repeatedly. When a host has several addresses, connections
+// rotate over them, falling back to the next one if an address can't be dialed. Failed lookups are
+// cached too, for at most 5 seconds. It only applies to the client's own transport, so it has no
+// effect with WithHTTPClient or WithCustomDoer.
+func WithDNSCache(ttl time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if ttl <= 0 {
+			cfg.addError(fmt.Errorf("invalid DNS cache TTL %s: must be positive", ttl))
+			return
+		}
+		cfg.DNSCacheTTL = ttl
+	}
+}
+
+// WithProxyRotation sends requests through a pool of proxies, picking one per request with the given
+// strategy. Combined with varying headers, it makes each request look distinct, as commonly needed for
+// scraping. Proxy URLs must be absolute http, https or socks5 URLs: invalid ones are skipped and
+// reported as option errors. It replaces the environment proxy settings, and has no effect with
+// WithHTTPClient or WithCustomDoer.
+func WithProxyRotation(proxies []string, strategy RotationStrategy) ClientOption {
 	return func(cfg *ClientConfig) {
-		if d > 0 {
-			cfg.Timeout = d
+		if strategy != RoundRobin && strategy != RandomRotation {
+			cfg.addError(fmt.Errorf("invalid proxy rotation strategy %d", strategy))
+			return
 		}
+
+		var urls []*url.URL
+		for _, raw := range proxies {
+			u, err := parseProxyURL(raw)
+			if err != nil {
+				cfg.addError(err)
+				continue
+			}
+			urls = append(urls, u)
+		}
+
+		cfg.Proxies = urls
+		cfg.ProxyRotation = strategy
 	}
 }
 
@@ -56,26 +488,171 @@ func WithTimeout(d time.Duration) ClientOption {
 func WithBaseURL(baseURL string) ClientOption {
 	return func(cfg *ClientConfig) {
 		if baseURL == "" {
+			cfg.addError(fmt.Errorf("empty base URL"))
 			return
 		}
 
 		u, err := normalizeBaseURL(baseURL)
 		if err != nil {
-			cfg.Logger.Error("invalid baseURL", "url", baseURL, "error", err)
+			logger.LogError(cfg.Logger, "invalid baseURL", err, "url", baseURL)
+			cfg.addError(err)
+			return
+		}
+
+		cfg.BaseURL = u
+		cfg.ResolveReference = false
+	}
+}
+
+// WithBaseURLNoNormalize sets the base URL like WithBaseURL, but keeps it as is, trailing slash
+// included, and resolves relative request paths against it with RFC 3986 reference resolution
+// (url.URL.ResolveReference), like a browser resolving a link, instead of appending them to its path.
+// The trailing slash is then significant:
+//
+//	base "http://host/api/", path "v2"    -> "http://host/api/v2"
+//	base "http://host/api",  path "v2"    -> "http://host/v2"
+//	base "http://host/api/", path "/v2"   -> "http://host/v2"
+//	base "http://host/api/", path "../v2" -> "http://host/v2"
+//
+// whereas with WithBaseURL both bases give "http://host/api/v2" for "v2" and "/v2".
+func WithBaseURLNoNormalize(baseURL string) ClientOption {
+	return func(cfg *ClientConfig) {
+		u, err := url.Parse(baseURL)
+		switch {
+		case baseURL == "":
+			err = fmt.Errorf("empty base URL")
+		case err != nil:
+			err = fmt.Errorf("invalid base URL: %w", err)
+		case !u.IsAbs():
+			err = fmt.Errorf("base URL must be absolute (have scheme and host)")
+		}
+		if err != nil {
+			cfg.addError(err)
 			return
 		}
 
 		cfg.BaseURL = u
+		cfg.ResolveReference = true
+	}
+}
+
+// WithBaseURLFunc selects the base URL of each request with a relative path by calling fn with the
+// request context, e.g. to route to a region or shard picked per request without building a client
+// for each. It takes precedence over WithBaseURL, whose URL is used when fn returns nil. The returned
+// URL must be absolute; it is joined with the path as set by WithBaseURL or WithBaseURLNoNormalize,
+// whichever is given. An error returned by fn aborts the request.
+func WithBaseURLFunc(fn func(ctx context.Context) (*url.URL, error)) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.BaseURLFunc = fn
+		}
+	}
+}
+
+// WithUserInfo embeds username and password in the userinfo of request URLs, for legacy endpoints
+// expecting credentials in the URL. net/http never sends userinfo on the wire: it is turned into a
+// Basic Authorization header, so this effectively means Basic auth. The header is only set when the
+// request doesn't already have one, so it never overrides a per-request or default Authorization.
+//
+// The credentials apply to relative paths and to absolute URLs on the base URL's host (any absolute
+// URL when no base URL is set). They replace userinfo embedded in the base URL, while an absolute
+// URL given per request with its own userinfo keeps it.
+func WithUserInfo(username, password string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if username == "" {
+			cfg.addError(fmt.Errorf("empty userinfo username"))
+			return
+		}
+		cfg.UserInfo = url.UserPassword(username, password)
 	}
 }
 
 // WithRetryAttempts configures the number of retries for failed requests.
-// A value of 0 disables retries entirely. Negative values are ignored.
-// Each retry follows an exponential backoff strategy.
+// Retries are disabled by default; a value of 0 disables them explicitly. Negative values are ignored.
+// Each retry follows an exponential backoff strategy, or the server's Retry-After when present.
+//
+// Only transient failures are retried (connection errors, 429 and 5xx statuses other than 501),
+// only for idempotent methods (see WithRetryMethods), and only when the request body can be rewound
+// (see RequestConfig).
+// A retry is skipped when its backoff would not leave enough time before the context deadline
+// for the request to complete; the last response or error is returned instead.
 func WithRetryAttempts(attempts int) ClientOption {
 	return func(cfg *ClientConfig) {
-		if attempts >= 0 {
-			cfg.RetryAttempts = attempts
+		if attempts < 0 {
+			cfg.addError(fmt.Errorf("invalid retry attempts %d: must not be negative", attempts))
+			return
+		}
+		cfg.RetryAttempts = attempts
+	}
+}
+
+// WithHedging reduces tail latency with hedged requests: when a request hasn't been answered within
+// after, the same request is sent again in parallel, and so on every after until max attempts are in
+// flight. The first response wins and the other attempts are canceled. A failed attempt doesn't end
+// the request while others are in flight.
+//
+// Hedging increases the load on the server, up to max times the requests for slow ones, so after
+// should be set around a high percentile of the normal latency, e.g. the 95th, which keeps the extra
+// load to a few percent. Only idempotent requests without a body, typically GETs, are hedged. It
+// applies to every Doer, including a custom one.
+func WithHedging(after time.Duration, max int) ClientOption {
+	return func(cfg *ClientConfig) {
+		if after <= 0 || max < 2 {
+			cfg.addError(fmt.Errorf("invalid hedging: delay %s must be positive and max attempts %d at least 2", after, max))
+			return
+		}
+		cfg.HedgeAfter = after
+		cfg.HedgeMax = max
+	}
+}
+
+// WithIdempotencyKey makes POST, PATCH and other non-idempotent requests retryable by sending them
+// with an idempotency key in the headerName header, or Idempotency-Key if empty. A random key is
+// generated once per call and kept across its retries, so a server supporting idempotency keys
+// can recognize duplicates instead of creating the resource twice. A key set through the request
+// headers is used as is. Retries still have to be enabled with WithRetryAttempts.
+func WithIdempotencyKey(headerName string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if headerName == "" {
+			headerName = defaultIdempotencyKeyHeader
+		}
+		cfg.IdempotencyKeyHeader = http.CanonicalHeaderKey(headerName)
+	}
+}
+
+// WithRetryMethods replaces the methods whose requests may be retried, by default the idempotent ones:
+// GET, HEAD, OPTIONS, TRACE, PUT and DELETE. Methods are case-insensitive.
+//
+// Retrying a non-idempotent method such as POST is only safe when the server handles duplicates: a
+// request that timed out may still have been processed, and retrying it would, say, create the
+// resource twice. Prefer WithIdempotencyKey, whose requests are retried whatever their method.
+func WithRetryMethods(methods ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if len(methods) == 0 {
+			cfg.addError(fmt.Errorf("no retry methods given"))
+			return
+		}
+
+		cfg.RetryMethods = make([]string, len(methods))
+		for i, method := range methods {
+			cfg.RetryMethods[i] = strings.ToUpper(method)
+		}
+	}
+}
+
+// WithRetryOn replaces the default retry predicate (transient errors, 429 and 5xx) with fn, called
+// after each attempt with its response and error, exactly one of them being non-nil. Returning true
+// triggers another attempt, still subject to WithRetryAttempts, the idempotent-method and rewindable
+// body rules, and the context deadline. This allows retrying, say, a 200 whose body reports
+// {"status":"pending"}.
+//
+// fn must not consume the body destructively: a response that isn't retried is returned to the
+// caller as is, so a predicate reading the body must replace resp.Body with a reader yielding the
+// same bytes. If nil is provided, the default predicate is kept.
+func WithRetryOn(fn func(resp *http.Response, err error) bool) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.RetryOn = fn
 		}
 	}
 }
@@ -95,8 +672,163 @@ func WithHeaders(headers map[string]string) ClientOption {
 	}
 }
 
+// WithDefaultQueryParams sets query parameters added to every request, such as an API key or a response format.
+// They apply to both relative paths and absolute URLs. A key set in RequestConfig.Params, or already present
+// in the URL's query string, takes precedence over its default.
+// The values are copied, so subsequent changes to the original won't affect the client.
+func WithDefaultQueryParams(params url.Values) ClientOption {
+	return func(cfg *ClientConfig) {
+		if len(params) == 0 {
+			return
+		}
+		if cfg.DefaultQueryParams == nil {
+			cfg.DefaultQueryParams = make(url.Values, len(params))
+		}
+		for k, values := range params {
+			cfg.DefaultQueryParams[k] = slices.Clone(values)
+		}
+	}
+}
+
+// WithDynamicUserAgent appends the result of fn, called with the request context, to the default
+// User-Agent of every request, e.g. to identify in upstream logs the tenant or operation a call was
+// made for:
+//
+//	client.WithDynamicUserAgent(func(ctx context.Context) string {
+//		return "tenant/" + TenantFromContext(ctx)
+//	})
+//
+// Nothing is appended when fn returns "", and a User-Agent set on the request itself is left as is.
+// It has no effect with WithCustomDoer. A nil fn is ignored.
+func WithDynamicUserAgent(fn func(ctx context.Context) string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.DynamicUserAgent = fn
+		}
+	}
+}
+
+// WithAccept sets a default Accept header listing mimeTypes in order of preference, weighted with
+// decreasing q-values: WithAccept("application/json", "text/plain") sends
+// "application/json, text/plain;q=0.9". Types that already carry a q parameter are kept as is.
+// A per-request Accept header takes precedence. Malformed types are skipped and reported like
+// other invalid options.
+func WithAccept(mimeTypes ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		var accepted []string
+		for _, mt := range mimeTypes {
+			mediaType, params, err := mime.ParseMediaType(mt)
+			if err != nil || !strings.Contains(mediaType, "/") {
+				cfg.addError(fmt.Errorf("invalid Accept media type %q", mt))
+				continue
+			}
+
+			if _, ok := params["q"]; !ok && len(accepted) > 0 {
+				mt = fmt.Sprintf("%s;q=0.%d", mt, max(10-len(accepted), 1))
+			}
+			accepted = append(accepted, mt)
+		}
+
+		if len(accepted) == 0 {
+			return
+		}
+
+		if cfg.Headers == nil {
+			cfg.Headers = make(map[string]string)
+		}
+		cfg.Headers["Accept"] = strings.Join(accepted, ", ")
+	}
+}
+
+// WithClearDefaultHeaders empties the default headers, the built-in User-Agent included, for full
+// control over what is sent: only headers set by later options, such as WithHeaders, and by each
+// request are added. Unless one of them sets it, no User-Agent is sent at all, rather than the one
+// net/http adds to requests without one.
+func WithClearDefaultHeaders() ClientOption {
+	return func(cfg *ClientConfig) {
+		// An empty User-Agent is how net/http is told not to send its own.
+		cfg.Headers = map[string]string{"User-Agent": ""}
+	}
+}
+
+// WithHeadersFromEnv adds default headers from environment variables named prefix followed by
+// the header name, with "_" separating the name segments. Each segment is title-cased, so with
+// the prefix "BRISA_HEADER_", BRISA_HEADER_X_API_KEY=abc becomes the header "X-Api-Key: abc".
+// A trailing "_" is added to the prefix if missing.
+//
+// This lets deployments inject headers such as auth tokens without code changes. Variables with
+// a malformed name or a value containing line breaks are skipped with a warning. Headers are read
+// when the option is applied and override those from earlier options.
+func WithHeadersFromEnv(prefix string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if prefix == "" {
+			cfg.addError(fmt.Errorf("empty environment header prefix"))
+			return
+		}
+		if !strings.HasSuffix(prefix, "_") {
+			prefix += "_"
+		}
+
+		for _, kv := range os.Environ() {
+			key, value, _ := strings.Cut(kv, "=")
+			suffix, ok := strings.CutPrefix(key, prefix)
+			if !ok {
+				continue
+			}
+
+			name, err := envToHeaderName(suffix)
+			if err == nil && strings.ContainsAny(value, "\r\n") {
+				err = fmt.Errorf("value contains line breaks")
+			}
+			if err != nil {
+				cfg.Logger.Warn("skipping malformed header environment variable", "name", key, logger.ErrorKey, err)
+				cfg.addError(fmt.Errorf("invalid header environment variable %s: %w", key, err))
+				continue
+			}
+
+			if cfg.Headers == nil {
+				cfg.Headers = make(map[string]string)
+			}
+			cfg.Headers[name] = value
+		}
+	}
+}
+
+// envToHeaderName converts an environment variable suffix such as "X_API_KEY" to a header name such as "X-Api-Key".
+func envToHeaderName(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("missing header name")
+	}
+
+	segments := strings.Split(s, "_")
+	for i, seg := range segments {
+		if seg == "" {
+			return "", fmt.Errorf("empty header name segment")
+		}
+		for _, r := range seg {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+				return "", fmt.Errorf("invalid character %q in header name", r)
+			}
+		}
+		segments[i] = strings.ToUpper(seg[:1]) + strings.ToLower(seg[1:])
+	}
+
+	return strings.Join(segments, "-"), nil
+}
+
+// WithHealthPath sets the path, relative to the base URL, that Client.Ping requests to check that
+// the server is up, e.g. "/healthz". An empty path is ignored.
+func WithHealthPath(path string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if path != "" {
+			cfg.HealthPath = path
+		}
+	}
+}
+
 // WithCookieJar provides a custom cookie jar for session management.
 // If nil is provided or the jar is not set, cookies will not be persisted between requests.
+// See WithCookieJarEnabled for a jar without setting one up.
 func WithCookieJar(jar *cookiejar.Jar) ClientOption {
 	return func(cfg *ClientConfig) {
 		if jar != nil {
@@ -105,8 +837,17 @@ func WithCookieJar(jar *cookiejar.Jar) ClientOption {
 	}
 }
 
+// WithCookieJarEnabled persists cookies between requests in a new in-memory jar, created by New,
+// for users who just want session cookies to work. A jar given with WithCookieJar takes precedence,
+// whatever the order of the options. Like WithCookieJar, it is ignored with WithHTTPClient.
+func WithCookieJarEnabled() ClientOption {
+	return func(cfg *ClientConfig) { cfg.CookieJarEnabled = true }
+}
+
 // WithLogger sets a custom logger for client operations.
 // If nil is provided, the client will use a no-op logger by default.
+// A logger stored in the request context with logger.ContextWithLogger takes precedence for that
+// request, without the fields of WithLoggerFields.
 func WithLogger(l logger.Logger) ClientOption {
 	return func(cfg *ClientConfig) {
 		if l != nil {
@@ -115,15 +856,114 @@ func WithLogger(l logger.Logger) ClientOption {
 	}
 }
 
+// WithClock sets the clock used for retry backoff, Retry-After delays, deadline checks, metrics
+// durations and slow request detection. It exists for tests, which can advance a fake clock instantly instead of
+// waiting in real time. If nil is provided, the real clock is kept.
+func WithClock(clock Clock) ClientOption {
+	return func(cfg *ClientConfig) {
+		if clock != nil {
+			cfg.Clock = clock
+		}
+	}
+}
+
+// WithLoggerFields adds static fields, such as a service or component name, to every log line of the
+// client. They are attached with Logger.WithFields when the client is built, so the logger given to
+// WithLogger is not modified, and combine with the fields of logger.ContextWithFields.
+// Calling it multiple times merges the fields.
+func WithLoggerFields(fields map[string]any) ClientOption {
+	return func(cfg *ClientConfig) {
+		if cfg.LoggerFields == nil {
+			cfg.LoggerFields = make(map[string]any, len(fields))
+		}
+		maps.Copy(cfg.LoggerFields, fields)
+	}
+}
+
 // WithDebug enables verbose logging of HTTP requests and responses.
 // When enabled, the logger will output detailed information including:
-// - Full request/response headers
+// - Full request/response headers, as a map with one key per header
 // - Request/response bodies (unless they contain binary data)
 // - Timing information
 func WithDebug(enable bool) ClientOption {
 	return func(cfg *ClientConfig) { cfg.Debug = enable }
 }
 
+// WithDebugDump adds the raw request and response header dumps, as sent and received on the wire,
+// to the debug logs enabled by WithDebug, under the "dump" field. They are verbose and hard to query
+// in log aggregation tools, so they are left out by default.
+func WithDebugDump(enable bool) ClientOption {
+	return func(cfg *ClientConfig) { cfg.DebugDump = enable }
+}
+
+// WithPrettyJSONLogs indents the JSON request and response bodies logged with WithDebug, so they are
+// readable when debugging APIs. Other bodies, and JSON ones that fail to parse, such as response
+// bodies truncated to the logging size cap, are logged verbatim.
+func WithPrettyJSONLogs() ClientOption {
+	return func(cfg *ClientConfig) { cfg.PrettyJSONLogs = true }
+}
+
+// WithConnTrace logs, at debug level, how each request got its connection: whether it was reused,
+// had been idle and for how long, and the remote address. This helps diagnosing connection churn,
+// e.g. bodies not being drained. Unlike WithDebug, it doesn't log requests and responses themselves.
+// It works with every Doer, as it relies on the request context.
+func WithConnTrace() ClientOption {
+	return func(cfg *ClientConfig) { cfg.ConnTrace = true }
+}
+
+// WithLogResolvedURL logs, at info level, the method and final URL of each request before it is sent:
+// the base URL joined with the path and path segments, with the default and per-request query
+// parameters merged in. Passwords in the URL are redacted. It is a lightweight way to diagnose base
+// URL and path mistakes, without the request and response dumps of WithDebug.
+func WithLogResolvedURL() ClientOption {
+	return func(cfg *ClientConfig) { cfg.LogResolvedURL = true }
+}
+
+// WithHealthWindow tracks the outcome of the last n requests sent to each host, exposed by
+// Client.HostHealth, so that applications can route around degraded hosts without a full circuit
+// breaker. It relies on the transport chain, so it has no effect with WithCustomDoer.
+func WithHealthWindow(n int) ClientOption {
+	return func(cfg *ClientConfig) {
+		if n <= 0 {
+			cfg.addError(fmt.Errorf("invalid health window %d: must be positive", n))
+			return
+		}
+		cfg.HealthWindow = n
+	}
+}
+
+// WithAdaptiveRateLimit makes the client respect the rate limit reported by the server: once a
+// response says no requests remain (see RateLimitInfo), the following requests wait until the
+// reported reset time before being sent, instead of being rejected by the server.
+// Waits honor the request context. It has no effect with WithCustomDoer.
+func WithAdaptiveRateLimit() ClientOption {
+	return func(cfg *ClientConfig) { cfg.AdaptiveRateLimit = true }
+}
+
+// WithSlowRequestThreshold logs a warning for every request whose round trip takes longer than d,
+// with its method, URL and duration, even when debug logging is off. This gives visibility into
+// slow calls without full request/response dumps. Only the round trip is timed: up to the
+// response headers, not reading the body. Each retry attempt is measured on its own.
+func WithSlowRequestThreshold(d time.Duration) ClientOption {
+	return func(cfg *ClientConfig) {
+		if d <= 0 {
+			cfg.addError(fmt.Errorf("invalid slow request threshold %s: must be positive", d))
+			return
+		}
+		cfg.SlowRequestThreshold = d
+	}
+}
+
+// WithMetrics reports every request attempt, including retries, to the given recorder.
+// A PrometheusRecorder is available when building with the "prometheus" tag.
+func WithMetrics(r MetricsRecorder) ClientOption {
+	return func(cfg *ClientConfig) {
+		if r != nil {
+			cfg.Metrics = r
+		}
+	}
+}
+
 // WithCustomDoer allows injection of a custom HTTP client implementation.
 // This can be used to mock the client for testing or provide special transport logic.
 // The Doer interface must not be nil to take effect.
@@ -135,6 +975,498 @@ func WithCustomDoer(d Doer) ClientOption {
 	}
 }
 
+// WithMaxResponseSize limits the number of response body bytes that can be read.
+// Reading past n bytes fails with ErrResponseTooLarge, protecting against servers returning huge bodies.
+// The limit applies to every response returned by the client, including error responses.
+// It counts decoded bytes: when the transport transparently decompresses a gzip response,
+// the limit is enforced on the decompressed stream, which also defuses compression bombs.
+// A value of n <= 0 means unlimited, which is the default.
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(cfg *ClientConfig) { cfg.MaxResponseSize = n }
+}
+
+// WithMaxRequestSize limits request bodies to n bytes, a safety valve for services building them from
+// user input. A body whose length is known to exceed n fails with ErrRequestTooLarge before anything
+// is sent; for other bodies, the request is aborted with ErrRequestTooLarge as soon as the transport
+// reads past n bytes. It applies to bodies as sent, after request interceptors.
+// A value of n <= 0 means unlimited, which is the default.
+func WithMaxRequestSize(n int64) ClientOption {
+	return func(cfg *ClientConfig) { cfg.MaxRequestSize = n }
+}
+
+// WithBufferUnknownLength buffers request bodies of unknown length, up to max bytes, so they are sent
+// with a Content-Length instead of chunked transfer encoding, which some servers reject. Bodies are of
+// unknown length when they are plain io.Readers, as opposed to byte slices, *bytes.Buffer,
+// *bytes.Reader and *strings.Reader. A body larger than max is still sent, chunked.
+// Buffered bodies can also be replayed on redirects and retries. It overrides WithForceChunked.
+func WithBufferUnknownLength(max int64) ClientOption {
+	return func(cfg *ClientConfig) {
+		if max <= 0 {
+			cfg.addError(fmt.Errorf("invalid body buffer size %d: must be positive", max))
+			return
+		}
+		cfg.BufferBodySize = max
+		cfg.ForceChunked = false
+	}
+}
+
+// WithForceChunked always streams request bodies with chunked transfer encoding, without a
+// Content-Length, even when their length is known. Chunked encoding only exists in HTTP/1.1:
+// over HTTP/2 the body is streamed in frames instead. It overrides WithBufferUnknownLength.
+func WithForceChunked() ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.ForceChunked = true
+		cfg.BufferBodySize = 0
+	}
+}
+
+// WithErrorDecoder sets how the body of error responses (see WithStatusValidator) is decoded into
+// HTTPError.Details, typically to map an API error schema onto a Go type:
+//
+//	client.WithErrorDecoder(func(body []byte) (any, error) {
+//		var apiErr APIError
+//		err := json.Unmarshal(body, &apiErr)
+//		return apiErr, err
+//	})
+//
+// Without a decoder, or when it fails, Details holds the raw body as a string. Only the first 64KB of
+// the body are decoded, and the body stays readable from the start. A nil decoder is ignored.
+func WithErrorDecoder(fn func(body []byte) (any, error)) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.ErrorDecoder = fn
+		}
+	}
+}
+
+// WithDecoder registers dec for responses whose Content-Type is dec.ContentType(), parameters such as
+// charset being ignored, replacing any decoder registered for it. It is used by GetDecoded.
+// If nil is provided, it is ignored.
+func WithDecoder(dec Decoder) ClientOption {
+	return func(cfg *ClientConfig) {
+		if dec == nil {
+			return
+		}
+		if cfg.Decoders == nil {
+			cfg.Decoders = make(map[string]Decoder)
+		}
+		cfg.Decoders[dec.ContentType()] = dec
+	}
+}
+
+// WithDefaultDecoder sets the decoder used by GetDecoded when no decoder registered with WithDecoder
+// matches the response Content-Type. The default is JSONDecoder. If nil is provided, the default is kept.
+func WithDefaultDecoder(dec Decoder) ClientOption {
+	return func(cfg *ClientConfig) {
+		if dec != nil {
+			cfg.DefaultDecoder = dec
+		}
+	}
+}
+
+// WithDefaultEncoder sets the encoder of the request bodies built by Send, unless RequestConfig.Encoder
+// is set. The default is JSONEncoder.
+// If nil is provided, the default is kept.
+func WithDefaultEncoder(enc Encoder) ClientOption {
+	return func(cfg *ClientConfig) {
+		if enc != nil {
+			cfg.DefaultEncoder = enc
+		}
+	}
+}
+
+// WithAutoDrainOnError drains and closes the body of error responses (see WithStatusValidator) before
+// they are returned, so the connection goes back to the keep-alive pool even if the caller
+// never reads it. The error is still returned alongside the response, but its body is empty.
+// Leave it disabled when error bodies carry useful details.
+func WithAutoDrainOnError() ClientOption {
+	return func(cfg *ClientConfig) { cfg.AutoDrainOnError = true }
+}
+
+// WithRequestInterceptor adds an interceptor run on every request after it is built (URL resolved,
+// body and request headers set) and before it is sent. Interceptors run in the order they were added.
+// Unlike transport layers, they run once per call rather than per redirect, and have direct access
+// to the caller's context. Default headers are added later by the transport chain, so they are not
+// visible to interceptors.
+func WithRequestInterceptor(fn RequestInterceptor) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.Interceptors = append(cfg.Interceptors, fn)
+		}
+	}
+}
+
+// WithAWSV4Signer signs every request with AWS Signature Version 4, setting the Authorization and
+// X-Amz-Date headers, for AWS and AWS-compatible APIs (S3, MinIO, ...). For the "s3" service the
+// X-Amz-Content-Sha256 header is also set.
+// The body is hashed before sending, so it must be rewindable (see RequestConfig); requests with a
+// one-shot reader body fail. The signer runs as a request interceptor, after those added before it.
+func WithAWSV4Signer(accessKey, secretKey, region, service string) ClientOption {
+	return func(cfg *ClientConfig) {
+		if accessKey == "" || secretKey == "" || region == "" || service == "" {
+			cfg.addError(fmt.Errorf("invalid AWS SigV4 signer: access key, secret key, region and service are required"))
+			return
+		}
+
+		signer := &sigV4Signer{
+			accessKey: accessKey,
+			secretKey: secretKey,
+			region:    region,
+			service:   service,
+			now:       time.Now,
+		}
+		cfg.Interceptors = append(cfg.Interceptors, signer.sign)
+	}
+}
+
+// WithOAuth2ClientCredentials authenticates every request with an OAuth2 access token obtained with
+// the client credentials grant: the token is fetched from tokenURL on first use, with the client ID
+// and secret sent as HTTP Basic auth and the scopes, if any, then cached and refreshed shortly before
+// it expires. Concurrent requests share a single fetch.
+//
+// The token is set as a Bearer Authorization header by a request interceptor, so it works with every
+// Doer. When the token can't be fetched, the request fails with an error wrapping the cause, including
+// the error code returned by the token endpoint. The token endpoint is called with its own
+// http.Client, not through this client's transport chain.
+func WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes []string) ClientOption {
+	return func(cfg *ClientConfig) {
+		u, err := url.Parse(tokenURL)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			cfg.addError(fmt.Errorf("invalid OAuth2 token URL %q: must be an absolute URL", tokenURL))
+			return
+		}
+		if clientID == "" {
+			cfg.addError(fmt.Errorf("invalid OAuth2 client credentials: client ID is required"))
+			return
+		}
+
+		creds := &clientCredentials{
+			tokenURL:     tokenURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scopes:       slices.Clone(scopes),
+			doer:         &http.Client{Timeout: defaultTimeout},
+			now:          time.Now,
+		}
+		cfg.Interceptors = append(cfg.Interceptors, creds.authorize)
+	}
+}
+
+// WithAutoContentType sets the Content-Type of requests with a body but no Content-Type, neither
+// per-request nor as a default header. The type is detected from the first 512 bytes of the body
+// with http.DetectContentType; text that starts like a JSON object or array is sent as application/json.
+// The body is still sent in full.
+func WithAutoContentType() ClientOption {
+	return func(cfg *ClientConfig) { cfg.AutoContentType = true }
+}
+
+// WithResponseBodyInterceptor wraps the body of every successful response (status < 400) returned
+// to the caller. Interceptors are applied in the order they were added, so the last one added is
+// the outermost reader. They operate on the body as the caller sees it: already decompressed and
+// subject to WithMaxResponseSize. The wrapper's Close must close the wrapped body, otherwise the
+// connection can't be reused.
+func WithResponseBodyInterceptor(fn BodyInterceptor) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.BodyInterceptors = append(cfg.BodyInterceptors, fn)
+		}
+	}
+}
+
+// WithBodyValidator validates the body of every response accepted by the status validator, when it
+// has one. The body is buffered, up to 1MB, and passed to fn after response body interceptors are
+// applied; larger bodies are passed truncated. When fn returns an error, the response is returned
+// along with an *errors.HTTPError wrapping it. Either way the body stays readable from the start by
+// the caller. Validators run in the order they were added, stopping at the first error.
+func WithBodyValidator(fn BodyValidator) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.BodyValidators = append(cfg.BodyValidators, fn)
+		}
+	}
+}
+
+// WithResponseTransform adds a transform run once on every response, before it is checked, so
+// statuses can be remapped or headers rewritten in one place. Unlike body validators, transforms can
+// alter the response itself, or return a different one. They run in the order they were added:
+//
+//  1. response transforms
+//  2. the status validator (see WithStatusValidator)
+//  3. response body interceptors
+//  4. body validators
+//
+// When fn returns an error, the request fails with an *errors.HTTPError wrapping it, along with the
+// response as it was before fn ran. A transform returning a nil response is treated as an error.
+func WithResponseTransform(fn ResponseTransform) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.Transforms = append(cfg.Transforms, fn)
+		}
+	}
+}
+
+// WithTransportMiddleware adds mw as the outermost layer of the transport chain.
+// It is a shorthand for WithTransportMiddlewareAt(Outermost, mw).
+func WithTransportMiddleware(mw TransportMiddleware) ClientOption {
+	return WithTransportMiddlewareAt(Outermost, mw)
+}
+
+// WithTransportMiddlewareAt adds mw to the transport chain at the given layer, see TransportLayer
+// for where each one sits relative to the built-in layers. Middleware added at the same layer wraps
+// in order: the first one added is the outermost. It is not applied with WithCustomDoer.
+func WithTransportMiddlewareAt(layer TransportLayer, mw TransportMiddleware) ClientOption {
+	return func(cfg *ClientConfig) {
+		if layer < Outermost || layer > AfterRetry {
+			cfg.addError(fmt.Errorf("invalid transport layer %d", layer))
+			return
+		}
+		if mw == nil {
+			return
+		}
+
+		if cfg.Middlewares == nil {
+			cfg.Middlewares = make(map[TransportLayer][]TransportMiddleware)
+		}
+		cfg.Middlewares[layer] = append(cfg.Middlewares[layer], mw)
+	}
+}
+
+// WithStatusValidator sets the function deciding which response statuses are successful. Responses
+// it rejects are returned along with an *errors.HTTPError (see also WithAutoDrainOnError).
+// By default every status below 400 is accepted. If nil is provided, the default is kept.
+func WithStatusValidator(fn func(status int) bool) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.StatusValidator = fn
+		}
+	}
+}
+
+// WithDisableRedirects stops the default Doer from following redirects: 3xx responses are returned
+// as is, their target being available with resp.Location. Since the default status validator
+// accepts every status below 400, they are returned without error unless WithRedirectsAsErrors is
+// also used. It has no effect with WithHTTPClient or WithCustomDoer, whose redirect policy is theirs.
+func WithDisableRedirects() ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.DisableRedirects = true
+	}
+}
+
+// WithRedirectsAsErrors returns redirects that were not followed (see IsRedirect), whether because of
+// WithDisableRedirects or the redirect policy of the Doer, with an HTTPError wrapping ErrRedirect.
+// The response is returned along with the error so that its Location can still be read.
+// Redirects are checked before the status validator, which never sees them. Other 3xx responses,
+// such as 304 Not Modified, are left to the validator.
+func WithRedirectsAsErrors() ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.RedirectsAsErrors = true
+	}
+}
+
+// WithAutoDecompress negotiates the compression of responses and decodes them: requests advertise
+// the supported content codings in Accept-Encoding, br (brotli), gzip and deflate, plus zstd when
+// building with the "zstd" tag, and response bodies are decoded according to their Content-Encoding,
+// which is then removed along with Content-Length. Responses with other codings are returned as is.
+//
+// Without it, net/http only negotiates gzip. Requests setting their own Accept-Encoding are left
+// alone, their responses being returned undecoded. The limit of WithMaxResponseSize and the debug
+// logs apply to the decoded body.
+func WithAutoDecompress() ClientOption {
+	return func(cfg *ClientConfig) { cfg.AutoDecompress = true }
+}
+
+// WithFollowCreated fetches created resources: when a request other than a GET is answered with
+// 201 Created and a Location header, the response is discarded and a GET request for Location,
+// resolved against the request URL, is sent instead, its response being returned as that of the
+// original call. PostJSON, for instance, then decodes the created resource. There is a single
+// follow-up request, carrying the headers of the original one except Content-Type, as well as its
+// RequestConfig.MaxResponseSize and Trace, which then times the follow-up GET.
+//
+// A 201 is no redirect, so the follow-up happens whatever WithDisableRedirects; the response to the
+// follow-up GET is then subject to the redirect policy, like any other response.
+func WithFollowCreated() ClientOption {
+	return func(cfg *ClientConfig) { cfg.FollowCreated = true }
+}
+
+// WithSingleFlight coalesces concurrent identical requests, so that when several goroutines request
+// the same URL at the same time (e.g. a cache stampede) a single HTTP call is made and its response
+// is shared. Each caller gets its own copy of the response with an independently readable body,
+// which means shared responses are fully buffered in memory (bounded by WithMaxResponseSize).
+//
+// Only GET and HEAD requests without per-request headers are coalesced, keyed by method and final
+// URL, along with RequestConfig.MaxResponseSize when set; side-effecting methods are never
+// deduplicated. Nothing is coalesced while request
+// interceptors are configured (see WithRequestInterceptor), since they may set per-caller headers.
+// The call is made with the context of the first caller: if it is canceled, the other callers
+// waiting on the same call fail too.
+func WithSingleFlight() ClientOption {
+	return func(cfg *ClientConfig) { cfg.SingleFlight = true }
+}
+
+// WithCacheKeyIgnore leaves the given query parameters out of the keys requests are coalesced by (see
+// WithSingleFlight), e.g. a cache-busting "_t" timestamp: requests only differing by them share a
+// single call, made with the parameters of the first caller. Names are case-sensitive.
+func WithCacheKeyIgnore(params ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.CacheKeyIgnore = append(cfg.CacheKeyIgnore, params...)
+	}
+}
+
+// WithAllowedHosts restricts requests to the given hosts, which is useful when URLs come from untrusted input.
+// A host of the form "*.example.com" allows any subdomain of example.com.
+// Requests to other hosts, including redirect targets, fail with ErrHostNotAllowed. With WithCustomDoer,
+// only the host of the request handed to the Doer is checked, not the redirects it follows itself.
+// Calling it multiple times accumulates hosts. Any other use of "*" is invalid and never matches.
+func WithAllowedHosts(hosts ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.addHostPatternErrors(hosts)
+		cfg.AllowedHosts = append(cfg.AllowedHosts, hosts...)
+	}
+}
+
+// WithBlockedHosts rejects requests to the given hosts with ErrHostNotAllowed.
+// It uses the same matching rules as WithAllowedHosts and takes precedence over it.
+// Calling it multiple times accumulates hosts.
+func WithBlockedHosts(hosts ...string) ClientOption {
+	return func(cfg *ClientConfig) {
+		cfg.addHostPatternErrors(hosts)
+		cfg.BlockedHosts = append(cfg.BlockedHosts, hosts...)
+	}
+}
+
+// WithBlockPrivateNetworks rejects connections to loopback, private and link-local addresses.
+// The check is performed on the resolved IP right before dialing, which protects against
+// DNS-rebinding SSRF where a public hostname resolves to an internal address.
+// Note that when a proxy is in use, the address checked is the proxy's.
+// It can't be combined with WithHTTPClient, see there, nor with WithCustomDoer, which dials on its own.
+func WithBlockPrivateNetworks() ClientOption {
+	return func(cfg *ClientConfig) { cfg.BlockPrivateNetworks = true }
+}
+
+// WithStrictValidation makes New return an error when any option received invalid input.
+// By default invalid values are ignored and the defaults are kept, which can hide
+// configuration mistakes (e.g. an empty base URL or a negative timeout) until request time.
+// The position of this option among the others does not matter.
+func WithStrictValidation() ClientOption {
+	return func(cfg *ClientConfig) { cfg.StrictValidation = true }
+}
+
+// WithRequireBaseURL makes New return an error when no valid base URL, nor WithBaseURLFunc, was configured, for
+// applications that only use relative paths. Without it, a missing base URL is only reported
+// when the first relative request is made.
+func WithRequireBaseURL() ClientOption {
+	return func(cfg *ClientConfig) { cfg.RequireBaseURL = true }
+}
+
+// logger returns the logger of the client, carrying the static fields of WithLoggerFields.
+func (cfg *ClientConfig) logger() logger.Logger {
+	if len(cfg.LoggerFields) == 0 {
+		return cfg.Logger
+	}
+	return cfg.Logger.WithFields(maps.Clone(cfg.LoggerFields))
+}
+
+// addError records an invalid option value, reported by New under strict validation.
+func (cfg *ClientConfig) addError(err error) {
+	cfg.errs = append(cfg.errs, err)
+}
+
+// addHostPatternErrors records an error for each host pattern using "*" other than as a "*." prefix.
+func (cfg *ClientConfig) addHostPatternErrors(hosts []string) {
+	for _, h := range hosts {
+		if !validHostPattern(h) {
+			cfg.addError(fmt.Errorf("invalid host pattern %q: only a leading \"*.\" wildcard is supported", h))
+		}
+	}
+}
+
+// clone returns a copy of cfg that can be modified without affecting the original.
+// Maps and slices are copied; the cookie jar, logger and custom Doer are shared.
+// Previously recorded option errors are not carried over.
+func (cfg *ClientConfig) clone() *ClientConfig {
+	c := *cfg
+	c.errs = nil
+	c.health = nil
+
+	if cfg.BaseURL != nil {
+		u := *cfg.BaseURL
+		c.BaseURL = &u
+	}
+
+	c.Headers = maps.Clone(cfg.Headers)
+	c.LoggerFields = maps.Clone(cfg.LoggerFields)
+	c.Decoders = maps.Clone(cfg.Decoders)
+	c.DefaultQueryParams = make(url.Values, len(cfg.DefaultQueryParams))
+	for k, values := range cfg.DefaultQueryParams {
+		c.DefaultQueryParams[k] = slices.Clone(values)
+	}
+	c.Interceptors = slices.Clone(cfg.Interceptors)
+	c.BodyInterceptors = slices.Clone(cfg.BodyInterceptors)
+	c.BodyValidators = slices.Clone(cfg.BodyValidators)
+	c.Transforms = slices.Clone(cfg.Transforms)
+	c.Middlewares = make(map[TransportLayer][]TransportMiddleware, len(cfg.Middlewares))
+	for layer, mws := range cfg.Middlewares {
+		c.Middlewares[layer] = slices.Clone(mws)
+	}
+	c.AllowedHosts = slices.Clone(cfg.AllowedHosts)
+	c.BlockedHosts = slices.Clone(cfg.BlockedHosts)
+	c.Proxies = slices.Clone(cfg.Proxies)
+	c.RetryMethods = slices.Clone(cfg.RetryMethods)
+	c.CacheKeyIgnore = slices.Clone(cfg.CacheKeyIgnore)
+
+	return &c
+}
+
+// WithHTTPClient reuses an existing *http.Client, keeping its transport, timeout, cookie jar and
+// redirect policy, while still applying this package's base URL, default headers, logging and
+// request interceptors. The provided client is not modified: a copy is made whose transport is
+// wrapped by the client's transport chain.
+//
+// Precedence:
+//   - WithCustomDoer takes precedence: when both are set, the custom Doer is used as-is.
+//   - WithTimeout and WithCookieJar are ignored in favor of the http.Client's Timeout and Jar.
+//   - Options configuring the base transport (dial timeouts, keep-alives, HTTP version, ...) are
+//     ignored; the http.Client's transport, or http.DefaultTransport if nil, is used instead.
+//   - WithBlockPrivateNetworks, being enforced by the base transport's dialer, can't be applied:
+//     New returns an error when both are set.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(cfg *ClientConfig) {
+		if hc != nil {
+			cfg.HTTPClient = hc
+		}
+	}
+}
+
+// WithCaptureOnError calls fn whenever a request fails, on transport errors as on error statuses,
+// with a snapshot of the request as sent: method, URL, headers, default ones included, and body, when
+// it can be replayed, i.e. unless it was streamed. Combined with AsCurl, it helps reproduce failures
+// seen in production. resp is the response returned to the caller, nil when none was received; fn
+// must not read or close its body. Retried requests are captured once, when the last attempt fails.
+func WithCaptureOnError(fn CaptureFunc) ClientOption {
+	return func(cfg *ClientConfig) {
+		if fn != nil {
+			cfg.CaptureOnError = fn
+		}
+	}
+}
+
+// WithSharedTransport uses tr as the base transport instead of building one per client, so that
+// clients sharing it, e.g. many clients derived with Client.With, also share its connection pool.
+// Each client still builds its own transport chain on top of tr (headers, logging, retries,
+// middlewares, ...); only the connections are shared.
+//
+// tr is used as is: options configuring the base transport (timeouts, keep-alives, proxies, HTTP
+// version, ...) are ignored, New returns an error with WithBlockPrivateNetworks, and Close leaves its
+// idle connections alone, tr being owned by the caller. WithCustomDoer and WithHTTPClient take
+// precedence over it.
+func WithSharedTransport(tr *http.Transport) ClientOption {
+	return func(cfg *ClientConfig) {
+		if tr != nil {
+			cfg.SharedTransport = tr
+		}
+	}
+}
+
 // normalizeBaseURL parses and validates the given baseURL string.
 // It ensures the URL is absolute (has scheme and host) and removes any trailing slash from the path.
 // Returns a normalized *url.URL or an error if the input is invalid.
@@ -155,16 +1487,18 @@ func normalizeBaseURL(baseURL string) (*url.URL, error) {
 // buildConfig constructs a Config with defaults and applies all provided options.
 // Default values:
 // - Timeout: defaultTimeout (package-level constant)
+// - Dial, TLS handshake and response header timeouts: those of http.DefaultTransport
 // - Logger: logger.NoOp{}
-// - RetryAttempts: 3
+// - RetryAttempts: 0 (retries are opt-in, see WithRetryAttempts)
 // - Headers: Includes default User-Agent
 // Any invalid option values will fall back to their defaults.
 func buildConfig(opts ...ClientOption) *ClientConfig {
 	cfg := &ClientConfig{
-		Timeout:       defaultTimeout,
-		Logger:        logger.NoOp{},
-		RetryAttempts: 3,
-		Headers:       map[string]string{"User-Agent": defaultUserAgent},
+		Timeout:         defaultTimeout,
+		Logger:          logger.NoOp{},
+		Clock:           realClock{},
+		StatusValidator: defaultStatusValidator,
+		Headers:         map[string]string{"User-Agent": defaultUserAgent},
 	}
 
 	for _, opt := range opts {
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/glwbr/brisa/pkg/logger"
+)
+
+func TestNew_StrictValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "lenient by default",
+			opts: []ClientOption{WithTimeout(-1), WithBaseURL("")},
+		},
+		{
+			name: "strict with valid options",
+			opts: []ClientOption{WithStrictValidation(), WithTimeout(1), WithBaseURL("https://example.com")},
+		},
+		{
+			name:        "strict with negative timeout",
+			opts:        []ClientOption{WithStrictValidation(), WithTimeout(-1)},
+			wantErr:     true,
+			errContains: "invalid timeout",
+		},
+		{
+			name:        "strict with empty base URL",
+			opts:        []ClientOption{WithBaseURL(""), WithStrictValidation()},
+			wantErr:     true,
+			errContains: "empty base URL",
+		},
+		{
+			name:        "strict with relative base URL",
+			opts:        []ClientOption{WithStrictValidation(), WithBaseURL("/api")},
+			wantErr:     true,
+			errContains: "must be absolute",
+		},
+		{
+			name:        "strict with unsupported host wildcard",
+			opts:        []ClientOption{WithStrictValidation(), WithAllowedHosts("*.example.com", "*example.com")},
+			wantErr:     true,
+			errContains: "invalid host pattern",
+		},
+		{
+			name: "base URL required and set",
+			opts: []ClientOption{WithRequireBaseURL(), WithBaseURL("https://example.com")},
+		},
+		{
+			name:        "base URL required but missing",
+			opts:        []ClientOption{WithRequireBaseURL()},
+			wantErr:     true,
+			errContains: "base URL is required",
+		},
+		{
+			name:        "base URL required but invalid",
+			opts:        []ClientOption{WithBaseURL("not a url"), WithRequireBaseURL()},
+			wantErr:     true,
+			errContains: "base URL is required",
+		},
+		{
+			name:        "strict with negative retry attempts",
+			opts:        []ClientOption{WithStrictValidation(), WithRetryAttempts(-2)},
+			wantErr:     true,
+			errContains: "invalid retry attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error but got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Error message does not contain %q: %v", tt.errContains, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if c == nil {
+				t.Fatalf("Expected client, got nil")
+			}
+		})
+	}
+}
+
+func TestClient_With(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+
+	parent, err := New(
+		WithBaseURL("https://example.com/api"),
+		WithHeaders(map[string]string{"X-Tenant": "parent"}),
+		WithCookieJar(jar),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	child, err := parent.With(
+		WithBaseURL("https://other.com"),
+		WithHeaders(map[string]string{"X-Tenant": "child", "Authorization": "Bearer child"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := parent.baseURL.String(); got != "https://example.com/api" {
+		t.Errorf("Parent base URL changed to %q", got)
+	}
+	if got := child.baseURL.String(); got != "https://other.com" {
+		t.Errorf("Child base URL = %q, want %q", got, "https://other.com")
+	}
+
+	if got := parent.config.Headers["X-Tenant"]; got != "parent" {
+		t.Errorf("Parent header changed to %q", got)
+	}
+	if _, ok := parent.config.Headers["Authorization"]; ok {
+		t.Errorf("Child header leaked into parent")
+	}
+	if got := child.config.Headers["X-Tenant"]; got != "child" {
+		t.Errorf("Child header = %q, want %q", got, "child")
+	}
+	if got := child.config.Headers["User-Agent"]; got != defaultUserAgent {
+		t.Errorf("Child lost default header, got User-Agent %q", got)
+	}
+
+	if child.config.Jar != jar {
+		t.Errorf("Expected cookie jar to be shared")
+	}
+
+	if _, err := parent.With(WithStrictValidation(), WithTimeout(-1)); err == nil {
+		t.Errorf("Expected derived client to report invalid options")
+	}
+}
+
+func TestWithHeadersFromEnv(t *testing.T) {
+	t.Setenv("BRISA_HEADER_X_API_KEY", "abc")
+	t.Setenv("BRISA_HEADER_AUTHORIZATION", "Bearer token")
+	t.Setenv("BRISA_HEADER_X__BROKEN", "skipped")
+	t.Setenv("BRISA_HEADER_X_BAD_VALUE", "line\r\nInjected: yes")
+	t.Setenv("OTHER_HEADER_X_IGNORED", "ignored")
+
+	rec := newRecordingLogger()
+	cfg := buildConfig(WithLogger(rec), WithHeadersFromEnv("BRISA_HEADER"))
+
+	want := map[string]string{
+		"User-Agent":    defaultUserAgent,
+		"X-Api-Key":     "abc",
+		"Authorization": "Bearer token",
+	}
+	if len(cfg.Headers) != len(want) {
+		t.Errorf("Got headers %v, want %v", cfg.Headers, want)
+	}
+	for k, v := range want {
+		if cfg.Headers[k] != v {
+			t.Errorf("Header %s = %q, want %q", k, cfg.Headers[k], v)
+		}
+	}
+
+	warnings := 0
+	for _, e := range rec.Entries() {
+		if e.Level == logger.WarnLevel {
+			warnings++
+		}
+	}
+	if warnings != 2 {
+		t.Errorf("Logged %d warnings, want 2", warnings)
+	}
+}
+
+func TestWithAccept(t *testing.T) {
+	tests := []struct {
+		name      string
+		types     []string
+		want      string
+		wantWarns int
+	}{
+		{name: "single", types: []string{"application/json"}, want: "application/json"},
+		{name: "weighted", types: []string{"application/json", "text/plain", "*/*"}, want: "application/json, text/plain;q=0.9, */*;q=0.8"},
+		{name: "explicit weight kept", types: []string{"application/json", "text/*;q=0.5"}, want: "application/json, text/*;q=0.5"},
+		{name: "malformed skipped", types: []string{"json", "application/xml"}, want: "application/xml", wantWarns: 1},
+		{name: "all malformed", types: []string{"a b/c"}, want: "", wantWarns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecordingLogger()
+			c, err := New(WithLogger(rec), WithAccept(tt.types...))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := c.config.Headers["Accept"]; got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+
+			var warns int
+			for _, e := range rec.Entries() {
+				if e.Level == logger.WarnLevel {
+					warns++
+				}
+			}
+			if warns != tt.wantWarns {
+				t.Errorf("Logged %d warnings, want %d", warns, tt.wantWarns)
+			}
+		})
+	}
+}
+
+func TestWithCookieJarEnabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+			return
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithCookieJarEnabled())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"/login", "/me"} {
+		resp, err := c.Get(context.Background(), path, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error on %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	jar, _ := cookiejar.New(nil)
+	for _, opts := range [][]ClientOption{
+		{WithCookieJar(jar), WithCookieJarEnabled()},
+		{WithCookieJarEnabled(), WithCookieJar(jar)},
+	} {
+		c, _ := New(opts...)
+		if c.config.Jar != jar {
+			t.Errorf("Expected the explicit cookie jar to win")
+		}
+	}
+}
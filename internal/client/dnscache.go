@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxNegativeDNSCacheTTL caps how long a failed lookup is cached, so a host that was briefly
+// unresolvable is retried soon even with a long TTL.
+const maxNegativeDNSCacheTTL = 5 * time.Second
+
+// dialFunc is the signature of http.Transport.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dnsCache caches host lookups for the dialer of the base transport. See WithDNSCache.
+type dnsCache struct {
+	ttl    time.Duration
+	clock  Clock
+	lookup func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+// dnsEntry is a cached lookup result, either addresses or the error it failed with.
+type dnsEntry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+
+	// next is the index of the address to try first on the next dial, rotating over addrs.
+	next atomic.Uint32
+}
+
+// newDNSCache returns a cache keeping lookups for ttl, resolved with the default resolver.
+func newDNSCache(ttl time.Duration, clock Clock) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		clock:   clock,
+		lookup:  net.DefaultResolver.LookupHost,
+		entries: map[string]*dnsEntry{},
+	}
+}
+
+// dialContext wraps dial so host names are resolved through the cache. Addresses of a host are
+// used round-robin, the following ones being tried in turn if the first can't be dialed.
+func (c *dnsCache) dialContext(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		entry, err := c.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		start := int(entry.next.Add(1) - 1)
+		var errs []error
+		for i := range entry.addrs {
+			ip := entry.addrs[(start+i)%len(entry.addrs)]
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// resolve returns the cached lookup of host, looking it up again once expired.
+func (c *dnsCache) resolve(ctx context.Context, host string) (*dnsEntry, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry, entry.err
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	entry = &dnsEntry{addrs: addrs, err: err, expires: now.Add(c.ttl)}
+	if err != nil {
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the host.
+			return nil, err
+		}
+		entry.expires = now.Add(min(c.ttl, maxNegativeDNSCacheTTL))
+	}
+
+	c.mu.Lock()
+	c.entries[host] = entry
+	c.mu.Unlock()
+
+	return entry, err
+}
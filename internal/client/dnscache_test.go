@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDNSCache(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	lookups := 0
+	cache := newDNSCache(time.Minute, clock)
+	cache.lookup = func(_ context.Context, host string) ([]string, error) {
+		lookups++
+		if host == "missing.test" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{"10.0.0.1", "10.0.0.2"}, nil
+	}
+
+	var dialed []string
+	dial := cache.dialContext(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr == "10.0.0.2:80" {
+			return nil, errors.New("connection refused")
+		}
+		return nil, nil
+	})
+
+	for range 3 {
+		if _, err := dial(context.Background(), "tcp", "example.test:80"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// The second dial starts with the unreachable address and falls back to the first one.
+	want := []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.1:80", "10.0.0.1:80"}
+	if !slices.Equal(dialed, want) {
+		t.Errorf("Dialed %v, want %v", dialed, want)
+	}
+	if lookups != 1 {
+		t.Errorf("Made %d lookups, want 1", lookups)
+	}
+
+	clock.Sleep(context.Background(), time.Minute)
+	dial(context.Background(), "tcp", "example.test:80")
+	if lookups != 2 {
+		t.Errorf("Made %d lookups after expiry, want 2", lookups)
+	}
+
+	t.Run("negative caching", func(t *testing.T) {
+		lookups = 0
+		for range 2 {
+			if _, err := dial(context.Background(), "tcp", "missing.test:80"); err == nil {
+				t.Fatalf("Expected a lookup error")
+			}
+		}
+		if lookups != 1 {
+			t.Errorf("Made %d lookups, want 1", lookups)
+		}
+
+		clock.Sleep(context.Background(), maxNegativeDNSCacheTTL)
+		dial(context.Background(), "tcp", "missing.test:80")
+		if lookups != 2 {
+			t.Errorf("Made %d lookups after the negative TTL, want 2", lookups)
+		}
+	})
+
+	t.Run("IP address", func(t *testing.T) {
+		lookups, dialed = 0, nil
+		dial(context.Background(), "tcp", "192.0.2.1:443")
+		if lookups != 0 || !slices.Equal(dialed, []string{"192.0.2.1:443"}) {
+			t.Errorf("Got %d lookups and dialed %v, want the address dialed as is", lookups, dialed)
+		}
+	})
+}
+
+func TestClient_WithDNSCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c, err := New(WithDNSCache(time.Minute))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/glwbr/brisa/pkg/errors"
+)
+
+// DownloadConfig contains options for customizing downloads.
+type DownloadConfig struct {
+	Resume bool
+}
+
+// DownloadOption defines a function that modifies the DownloadConfig object.
+type DownloadOption func(*DownloadConfig)
+
+// WithResume makes Download continue a previously interrupted download instead of starting over.
+//
+// If dst already exists, a HEAD request first checks that the server supports ranges
+// ("Accept-Ranges: bytes"). If it does, a "Range: bytes=<size>-" request is sent and the response is
+// appended to dst when the server answers 206 Partial Content. Otherwise, or when the server answers
+// 200 with the full body anyway, dst is overwritten from the start.
+// A 416 Range Not Satisfiable for a file that already has the full size is treated as complete.
+//
+// To make resuming possible, data is written directly to dst as it arrives, so an interrupted
+// download leaves a partial file behind rather than nothing. The ETag (or Last-Modified) of the
+// response is kept in a hidden ".<name>.resume" file next to dst until the download completes,
+// and sent as If-Range when resuming: if the remote file changed in between, the server sends it
+// in full instead of appending new bytes to stale ones.
+func WithResume(enable bool) DownloadOption {
+	return func(cfg *DownloadConfig) { cfg.Resume = enable }
+}
+
+// Download sends a GET request and streams the response body to the file at dst, returning
+// the number of bytes written. The body is never buffered in memory, so it is suitable for large files.
+//
+// Missing parent directories of dst are created. The body is first written to a temporary file in
+// the same directory, which is renamed to dst only once the download completes, so dst is never left
+// half-written: on any error, including a canceled context, the temporary file is removed and an
+// existing dst is left untouched. WithResume changes this, see its documentation.
+func (c *Client) Download(ctx context.Context, path, dst string, opts *RequestConfig, dlOpts ...DownloadOption) (int64, error) {
+	cfg := &DownloadConfig{}
+	for _, opt := range dlOpts {
+		opt(cfg)
+	}
+
+	if cfg.Resume {
+		return c.resumeDownload(ctx, path, dst, opts)
+	}
+
+	resp, err := c.Get(ctx, path, opts)
+	if err != nil {
+		DrainAndClose(resp)
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return writeFileAtomic(dst, resp.Body)
+}
+
+// resumeDownload downloads path into dst, requesting only the bytes missing from an existing dst.
+func (c *Client) resumeDownload(ctx context.Context, path, dst string, opts *RequestConfig) (int64, error) {
+	var offset int64
+	if info, err := os.Stat(dst); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return 0, errors.Wrap(err, "failed to inspect destination file")
+	}
+
+	validatorFile := resumeValidatorPath(dst)
+
+	if offset > 0 {
+		ranges, err := c.acceptsRanges(ctx, path, opts)
+		if err != nil {
+			return 0, err
+		}
+
+		if ranges {
+			getOpts := withRequestHeader(opts, "Range", fmt.Sprintf("bytes=%d-", offset))
+			if validator, err := os.ReadFile(validatorFile); err == nil && len(validator) > 0 {
+				getOpts = withRequestHeader(getOpts, "If-Range", string(validator))
+			}
+			opts = getOpts
+		}
+	}
+
+	resp, err := c.Get(ctx, path, opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			if _, total, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+				DrainAndClose(resp)
+				os.Remove(validatorFile)
+				return 0, nil
+			}
+		}
+		DrainAndClose(resp)
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if resp.StatusCode == http.StatusPartialContent {
+		start, _, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return 0, errors.New("server returned an unexpected range: " + resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	} else {
+		// The range was ignored, or the remote file changed: start over.
+		flags |= os.O_TRUNC
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return 0, errors.Wrap(err, "failed to create destination directory")
+	}
+
+	if validator := resumeValidator(resp.Header); validator != "" {
+		if err := os.WriteFile(validatorFile, []byte(validator), 0o644); err != nil {
+			return 0, errors.Wrap(err, "failed to save resume validator")
+		}
+	} else {
+		os.Remove(validatorFile)
+	}
+
+	f, err := os.OpenFile(dst, flags, 0o644)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open destination file")
+	}
+
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, errors.Wrap(err, "failed to write response body")
+	}
+
+	os.Remove(validatorFile)
+	return n, nil
+}
+
+// acceptsRanges sends a HEAD request for path and reports whether the server advertises byte
+// range support. Servers rejecting HEAD are assumed not to support ranges.
+func (c *Client) acceptsRanges(ctx context.Context, path string, opts *RequestConfig) (bool, error) {
+	resp, err := c.Head(ctx, path, opts)
+	DrainAndClose(resp)
+	if err != nil {
+		if resp != nil {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, unit := range strings.Split(resp.Header.Get("Accept-Ranges"), ",") {
+		if strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resumeValidator returns the value to send as If-Range when resuming the download of a response
+// with the given headers: its strong ETag, or else its Last-Modified date. If-Range doesn't accept
+// weak ETags.
+func resumeValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// resumeValidatorPath returns the path of the file holding the resume validator for dst.
+func resumeValidatorPath(dst string) string {
+	return filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".resume")
+}
+
+// parseContentRange parses a "bytes <start>-<end>/<total>" or "bytes */<total>" Content-Range header.
+// The total is -1 when unknown ("*").
+func parseContentRange(header string) (start, total int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+
+	rng, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	total = -1
+	if size != "*" {
+		var err error
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	if rng == "*" {
+		return 0, total, true
+	}
+
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return start, total, true
+}
+
+// writeFileAtomic copies r into a temporary file next to dst and renames it to dst on success.
+func writeFileAtomic(dst string, r io.Reader) (n int64, err error) {
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, errors.Wrap(err, "failed to create destination directory")
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create temporary file")
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if n, err = io.Copy(tmp, r); err != nil {
+		return n, errors.Wrap(err, "failed to write response body")
+	}
+
+	if err = tmp.Chmod(0o644); err != nil {
+		return n, errors.Wrap(err, "failed to set file permissions")
+	}
+
+	if err = tmp.Close(); err != nil {
+		return n, errors.Wrap(err, "failed to close temporary file")
+	}
+
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return n, errors.Wrap(err, "failed to move downloaded file into place")
+	}
+
+	return n, nil
+}
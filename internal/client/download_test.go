@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Download(t *testing.T) {
+	const content = "invoice contents"
+
+	block := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	})
+	mux.HandleFunc("/stall", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		<-block
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer close(block)
+
+	c, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("writes file and creates directories", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "nested", "dir", "invoice.txt")
+
+		n, err := c.Download(context.Background(), "/file", dst, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n != int64(len(content)) {
+			t.Errorf("Wrote %d bytes, want %d", n, len(content))
+		}
+
+		got, err := os.ReadFile(dst)
+		if err != nil {
+			t.Fatalf("Failed to read downloaded file: %v", err)
+		}
+		if string(got) != content {
+			t.Errorf("Got %q, want %q", got, content)
+		}
+		assertOnlyFiles(t, filepath.Dir(dst), "invoice.txt")
+	})
+
+	t.Run("error status leaves no file", func(t *testing.T) {
+		dir := t.TempDir()
+
+		if _, err := c.Download(context.Background(), "/missing", filepath.Join(dir, "out"), nil); err == nil {
+			t.Fatalf("Expected error but got nil")
+		}
+		assertOnlyFiles(t, dir)
+	})
+
+	t.Run("cancellation removes temporary file", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		errc := make(chan error, 1)
+		go func() {
+			_, err := c.Download(ctx, "/stall", filepath.Join(dir, "out"), nil)
+			errc <- err
+		}()
+
+		// Wait for the temporary file to show up before canceling.
+		for {
+			if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+
+		if err := <-errc; err == nil {
+			t.Fatalf("Expected error but got nil")
+		}
+		assertOnlyFiles(t, dir)
+	})
+}
+
+// assertOnlyFiles fails unless dir contains exactly the named files.
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+
+	if len(got) != len(names) {
+		t.Fatalf("Directory contains %v, want %v", got, names)
+	}
+	for i := range names {
+		if got[i] != names[i] {
+			t.Fatalf("Directory contains %v, want %v", got, names)
+		}
+	}
+}
+
+func TestClient_DownloadResume(t *testing.T) {
+	const content = "0123456789"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ranged", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		existing string
+		wantN    int64
+	}{
+		{name: "no existing file", path: "/ranged", wantN: 10},
+		{name: "resumes partial file", path: "/ranged", existing: "01234", wantN: 5},
+		{name: "already complete", path: "/ranged", existing: content, wantN: 0},
+		{name: "restarts without range support", path: "/plain", existing: "01234", wantN: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "file")
+			if tt.existing != "" {
+				if err := os.WriteFile(dst, []byte(tt.existing), 0o644); err != nil {
+					t.Fatalf("Failed to write existing file: %v", err)
+				}
+			}
+
+			n, err := c.Download(context.Background(), tt.path, dst, nil, WithResume(true))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if n != tt.wantN {
+				t.Errorf("Wrote %d bytes, want %d", n, tt.wantN)
+			}
+
+			got, _ := os.ReadFile(dst)
+			if string(got) != content {
+				t.Errorf("Got %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadResume_NoRanges(t *testing.T) {
+	const content = "0123456789"
+
+	var gotRange atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gotRange.Store(r.Header.Get("Range"))
+		}
+		w.Header().Set("Accept-Ranges", "none")
+		io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dst, []byte("01234"), 0o644); err != nil {
+		t.Fatalf("Failed to write existing file: %v", err)
+	}
+
+	n, err := c.Download(context.Background(), "/", dst, nil, WithResume(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("Wrote %d bytes, want %d", n, len(content))
+	}
+	if r := gotRange.Load(); r != "" {
+		t.Errorf("Sent Range %q to a server without range support", r)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != content {
+		t.Errorf("Got %q, want %q", got, content)
+	}
+}
+
+func TestClient_DownloadResume_RemoteChanged(t *testing.T) {
+	const content = "abcdefghij"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A partial download of a previous version of the file.
+	dst := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dst, []byte("01234"), 0o644); err != nil {
+		t.Fatalf("Failed to write existing file: %v", err)
+	}
+	if err := os.WriteFile(resumeValidatorPath(dst), []byte(`"v1"`), 0o644); err != nil {
+		t.Fatalf("Failed to write validator: %v", err)
+	}
+
+	if _, err := c.Download(context.Background(), "/", dst, nil, WithResume(true)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got, _ := os.ReadFile(dst); string(got) != content {
+		t.Errorf("Got %q, want %q", got, content)
+	}
+	if _, err := os.Stat(resumeValidatorPath(dst)); !os.IsNotExist(err) {
+		t.Errorf("Expected the validator file to be removed once complete, got %v", err)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantStart int64
+		wantTotal int64
+		wantOK    bool
+	}{
+		{header: "bytes 5-9/10", wantStart: 5, wantTotal: 10, wantOK: true},
+		{header: "bytes 0-99/*", wantStart: 0, wantTotal: -1, wantOK: true},
+		{header: "bytes */10", wantStart: 0, wantTotal: 10, wantOK: true},
+		{header: "", wantOK: false},
+		{header: "items 0-1/2", wantOK: false},
+		{header: "bytes x-1/2", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			start, total, ok := parseContentRange(tt.header)
+			if ok != tt.wantOK || (ok && (start != tt.wantStart || total != tt.wantTotal)) {
+				t.Errorf("parseContentRange(%q) = %d, %d, %v; want %d, %d, %v",
+					tt.header, start, total, ok, tt.wantStart, tt.wantTotal, tt.wantOK)
+			}
+		})
+	}
+}
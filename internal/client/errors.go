@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/errors"
+)
+
+// ErrHostNotAllowed is returned when the request target is rejected by the
+// configured allowlist, denylist or private network protection.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// ErrResponseTooLarge is returned while reading a response body that exceeds the configured maximum size.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ErrRequestTooLarge is returned when a request body exceeds the maximum size set with WithMaxRequestSize.
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// ErrTooManyHeaders is returned when a response carries more header fields than allowed by
+// WithMaxResponseHeaders.
+var ErrTooManyHeaders = errors.New("too many response headers")
+
+// ErrRedirect is returned along with redirects that were not followed, see WithRedirectsAsErrors.
+var ErrRedirect = errors.New("unfollowed redirect")
+
+// ErrTimeout is returned when a request fails because a deadline was exceeded,
+// either the context deadline or the client timeout. Timeouts are usually safe to retry.
+var ErrTimeout = errors.New("request timed out")
+
+// ErrCanceled is returned when a request fails because its context was canceled by the caller.
+// Unlike ErrTimeout, it should not be retried.
+var ErrCanceled = errors.New("request canceled")
+
+// ErrClientClosed is returned by requests made with a client after its Close method was called.
+var ErrClientClosed = errors.New("client closed")
+
+// classifyError tags a failed request error with ErrTimeout or ErrCanceled when the failure
+// was caused by a deadline or a cancellation, keeping the original error in the chain.
+func classifyError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case context.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	// http.Client reports its own Timeout through a *url.Error rather than the context.
+	if te, ok := err.(interface{ Timeout() bool }); ok && te.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	return err
+}
+
+// TimeoutError is the error of a request that timed out, found with errors.As, telling which request
+// gave up and after how long. It wraps the original error tagged with ErrTimeout.
+type TimeoutError struct {
+	Method string
+	// URL is the request URL, with its password redacted.
+	URL string
+
+	// Elapsed is the time spent from when the request was sent until it failed, retries included.
+	Elapsed time.Duration
+	// Timeout is the time the request was given when sent: what remained before the context deadline,
+	// or the client timeout without one. It is 0 when unknown, e.g. with a custom Doer's own timeout.
+	Timeout time.Duration
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("%s %s timed out after %s", e.Method, e.URL, e.Elapsed.Round(time.Millisecond))
+	if e.Timeout > 0 {
+		msg += fmt.Sprintf(" (timeout %s)", e.Timeout.Round(time.Millisecond))
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original error, tagged with ErrTimeout.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// timeoutError returns err, the classified error of req sent at start, as a *TimeoutError when it is a
+// timeout.
+func (c *Client) timeoutError(req *http.Request, start time.Time, err error) error {
+	if !stderrors.Is(err, ErrTimeout) {
+		return err
+	}
+
+	var timeout time.Duration
+	if deadline, ok := req.Context().Deadline(); ok {
+		timeout = deadline.Sub(start)
+	} else if hc, ok := c.doer.(*http.Client); ok {
+		timeout = hc.Timeout
+	}
+
+	return &TimeoutError{
+		Method:  req.Method,
+		URL:     req.URL.Redacted(),
+		Elapsed: c.config.Clock.Now().Sub(start),
+		Timeout: timeout,
+		Err:     err,
+	}
+}
+
+// HTTPError is the error returned along with responses rejected by the status validator. It extends
+// errors.HTTPError, which stays reachable with errors.As, with the decoded error response body.
+type HTTPError struct {
+	*errors.HTTPError
+
+	// Details is the error response body, decoded by the WithErrorDecoder function if any, or as a
+	// string otherwise. It is nil when the body is empty.
+	Details any
+}
+
+// Unwrap returns the underlying errors.HTTPError.
+func (e *HTTPError) Unwrap() error {
+	return e.HTTPError
+}
+
+// AsHTTPError finds the first HTTP error in err's chain. Errors of failed requests, which are
+// errors.HTTPError without a response, are returned as an HTTPError without details:
+//
+//	if he, ok := client.AsHTTPError(err); ok {
+//		apiErr, _ := he.Details.(APIError)
+//	}
+func AsHTTPError(err error) (*HTTPError, bool) {
+	var he *HTTPError
+	if stderrors.As(err, &he) {
+		return he, true
+	}
+
+	var base *errors.HTTPError
+	if stderrors.As(err, &base) {
+		return &HTTPError{HTTPError: base}, true
+	}
+
+	return nil, false
+}
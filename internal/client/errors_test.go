@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_TimeoutAndCancel(t *testing.T) {
+	// slowDoer blocks until the request context is done.
+	slowDoer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(time.Second):
+			return stringResponse(http.StatusOK, "too late"), nil
+		}
+	})
+
+	c, err := New(WithCustomDoer(slowDoer))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		_, err := c.Get(ctx, "https://example.com", nil)
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout, got %v", err)
+		}
+		if errors.Is(err, ErrCanceled) {
+			t.Errorf("Timeout must not match ErrCanceled")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected original error to be preserved, got %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+
+		_, err := c.Get(ctx, "https://example.com", nil)
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+		if errors.Is(err, ErrTimeout) {
+			t.Errorf("Cancellation must not match ErrTimeout")
+		}
+	})
+}
+
+func TestClient_TimeoutError(t *testing.T) {
+	// slowDoer blocks until the request context is done.
+	slowDoer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	c, err := New(WithCustomDoer(slowDoer), WithContextTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = c.Get(context.Background(), "https://example.com/slow", nil)
+
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("Expected a *TimeoutError, got %v", err)
+	}
+	if te.Method != http.MethodGet || te.URL != "https://example.com/slow" {
+		t.Errorf("Got %s %s, want GET https://example.com/slow", te.Method, te.URL)
+	}
+	if te.Timeout <= 0 || te.Timeout > 20*time.Millisecond {
+		t.Errorf("Got timeout %s, want at most 20ms", te.Timeout)
+	}
+	if te.Elapsed < te.Timeout {
+		t.Errorf("Got elapsed %s, want at least the %s timeout", te.Elapsed, te.Timeout)
+	}
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout and the original error in the chain, got %v", err)
+	}
+
+	t.Run("other failures", func(t *testing.T) {
+		c, _ := New(WithCustomDoer(doerFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})))
+
+		_, err := c.Get(context.Background(), "https://example.com", nil)
+		if errors.As(err, &te) {
+			t.Errorf("Expected no *TimeoutError, got %v", te)
+		}
+	})
+}
+
+// timeoutErr mimics the *url.Error returned when http.Client.Timeout fires.
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "Client.Timeout exceeded" }
+func (timeoutErr) Timeout() bool { return true }
+
+func TestClassifyError_ClientTimeout(t *testing.T) {
+	err := classifyError(context.Background(), timeoutErr{})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+
+	plain := errors.New("connection refused")
+	if got := classifyError(context.Background(), plain); got != plain {
+		t.Errorf("Expected unrelated error to pass through, got %v", got)
+	}
+}
+
+func TestClient_ErrorDetails(t *testing.T) {
+	type apiError struct {
+		Code string `json:"code"`
+	}
+
+	decodeAPIError := func(body []byte) (any, error) {
+		var apiErr apiError
+		err := json.Unmarshal(body, &apiErr)
+		return apiErr, err
+	}
+
+	tests := []struct {
+		name string
+		body string
+		opts []ClientOption
+		want any
+	}{
+		{name: "raw body by default", body: `{"code":"quota"}`, want: `{"code":"quota"}`},
+		{name: "decoded", body: `{"code":"quota"}`, opts: []ClientOption{WithErrorDecoder(decodeAPIError)}, want: apiError{Code: "quota"}},
+		{name: "decoder failure", body: "Bad Gateway", opts: []ClientOption{WithErrorDecoder(decodeAPIError)}, want: "Bad Gateway"},
+		{name: "empty body", body: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				return stringResponse(http.StatusBadRequest, tt.body), nil
+			})
+			c, _ := New(append(tt.opts, WithCustomDoer(doer))...)
+
+			resp, err := c.Get(context.Background(), "https://example.com", nil)
+			he, ok := AsHTTPError(err)
+			if !ok {
+				t.Fatalf("Expected an HTTPError, got %v", err)
+			}
+			if he.Details != tt.want {
+				t.Errorf("Got details %#v, want %#v", he.Details, tt.want)
+			}
+			if he.StatusCode() != http.StatusBadRequest {
+				t.Errorf("Got status %d, want %d", he.StatusCode(), http.StatusBadRequest)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.body {
+				t.Errorf("Got body %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAsHTTPError_RequestFailure(t *testing.T) {
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	c, _ := New(WithCustomDoer(doer))
+
+	_, err := c.Get(context.Background(), "https://example.com", nil)
+	he, ok := AsHTTPError(err)
+	if !ok {
+		t.Fatalf("Expected an HTTPError, got %v", err)
+	}
+	if he.Details != nil || he.Response != nil {
+		t.Errorf("Expected no response nor details, got %+v", he)
+	}
+
+	if _, ok := AsHTTPError(errors.New("other")); ok {
+		t.Errorf("Expected no HTTPError for unrelated errors")
+	}
+}
+
+func TestClient_ContextAndClientTimeouts(t *testing.T) {
+	// slowDoer answers after 100ms unless the request context is done first.
+	slowDoer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(100 * time.Millisecond):
+			return stringResponse(http.StatusOK, "slow"), nil
+		}
+	})
+
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		wantTimeout bool
+	}{
+		{name: "context timeout with custom doer", opts: []ClientOption{WithCustomDoer(slowDoer), WithContextTimeout(10 * time.Millisecond)}, wantTimeout: true},
+		{name: "client timeout ignored by custom doer", opts: []ClientOption{WithCustomDoer(slowDoer), WithClientTimeout(10 * time.Millisecond)}},
+		{name: "context timeout long enough", opts: []ClientOption{WithCustomDoer(slowDoer), WithContextTimeout(time.Second)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Get(context.Background(), "https://example.com", nil)
+			if tt.wantTimeout {
+				if !errors.Is(err, ErrTimeout) {
+					t.Errorf("Expected ErrTimeout, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "slow" {
+				t.Errorf("Got body %q with error %v, want it readable", body, err)
+			}
+			resp.Body.Close()
+		})
+	}
+
+	t.Run("client timeout with default doer", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer srv.Close()
+
+		c, _ := New(WithClientTimeout(10 * time.Millisecond))
+		if _, err := c.Get(context.Background(), srv.URL, nil); !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// hostHealth keeps the outcome of the last requests sent to each host. See WithHealthWindow.
+type hostHealth struct {
+	window int
+
+	mu    sync.Mutex
+	hosts map[string]*outcomeWindow
+}
+
+// outcomeWindow is a ring buffer of the last request outcomes of a host, true for a success.
+type outcomeWindow struct {
+	outcomes  []bool
+	next      int
+	successes int
+}
+
+// newHostHealth returns a tracker keeping the last window outcomes of each host.
+func newHostHealth(window int) *hostHealth {
+	return &hostHealth{window: window, hosts: map[string]*outcomeWindow{}}
+}
+
+// record adds the outcome of a request to host, dropping the oldest one once the window is full.
+func (h *hostHealth) record(host string, success bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w, ok := h.hosts[host]
+	if !ok {
+		w = &outcomeWindow{outcomes: make([]bool, 0, h.window)}
+		h.hosts[host] = w
+	}
+
+	if len(w.outcomes) < h.window {
+		w.outcomes = append(w.outcomes, success)
+	} else {
+		if w.outcomes[w.next] {
+			w.successes--
+		}
+		w.outcomes[w.next] = success
+		w.next = (w.next + 1) % h.window
+	}
+
+	if success {
+		w.successes++
+	}
+}
+
+// stats returns the success rate and number of outcomes recorded for host.
+func (h *hostHealth) stats(host string) (float64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w, ok := h.hosts[host]
+	if !ok || len(w.outcomes) == 0 {
+		return 0, 0
+	}
+	return float64(w.successes) / float64(len(w.outcomes)), len(w.outcomes)
+}
+
+// healthTransport records the outcome of every request going through it in a hostHealth.
+// It sits inside the retry layer, so each attempt counts separately.
+type healthTransport struct {
+	Next   http.RoundTripper
+	Health *hostHealth
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+// A request fails when no response is received or the status is 5xx; other statuses are the
+// client's concern rather than a sign of a degraded host.
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next().RoundTrip(req)
+
+	// Requests given up by the caller say nothing about the host.
+	if req.Context().Err() == nil {
+		t.Health.record(req.URL.Host, err == nil && resp.StatusCode < 500)
+	}
+
+	return resp, err
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *healthTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// HostHealth returns the share of successful requests among the last ones sent to host, as in the
+// request URL with its port if any, and the number of requests it is computed from, at most the
+// window set with WithHealthWindow. A request is successful when a response is received with a
+// status below 500, each retry counting separately.
+// It returns 0 samples for hosts never requested and when WithHealthWindow isn't used.
+func (c *Client) HostHealth(host string) (successRate float64, samples int) {
+	if c.health == nil {
+		return 0, 0
+	}
+	return c.health.stats(host)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHostHealth_Window(t *testing.T) {
+	h := newHostHealth(4)
+
+	if rate, samples := h.stats("example.com"); rate != 0 || samples != 0 {
+		t.Errorf("Got %v over %d samples for an unknown host, want none", rate, samples)
+	}
+
+	for _, success := range []bool{false, false, true, true} {
+		h.record("example.com", success)
+	}
+	if rate, samples := h.stats("example.com"); rate != 0.5 || samples != 4 {
+		t.Errorf("Got %v over %d samples, want 0.5 over 4", rate, samples)
+	}
+
+	// The two failures fall out of the window.
+	h.record("example.com", true)
+	h.record("example.com", true)
+	if rate, samples := h.stats("example.com"); rate != 1 || samples != 4 {
+		t.Errorf("Got %v over %d samples, want 1 over 4", rate, samples)
+	}
+}
+
+func TestClient_HostHealth(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithHealthWindow(10))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 4 {
+		resp, _ := c.Get(context.Background(), "/", nil)
+		DrainAndClose(resp)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	if rate, samples := c.HostHealth(u.Host); rate != 0.75 || samples != 4 {
+		t.Errorf("Got %v over %d samples, want 0.75 over 4", rate, samples)
+	}
+
+	plain, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, samples := plain.HostHealth(u.Host); samples != 0 {
+		t.Errorf("Expected no tracking without WithHealthWindow, got %d samples", samples)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// hedgedResult is the outcome of one of the parallel attempts of a hedged request.
+type hedgedResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+}
+
+// hedgingDoer sends a request again, in parallel, when it hasn't been answered within After, up to
+// Max attempts in flight, and returns the first response. See WithHedging.
+type hedgingDoer struct {
+	Next  Doer
+	After time.Duration
+	Max   int
+}
+
+// Do implements the Doer interface.
+func (d *hedgingDoer) Do(req *http.Request) (*http.Response, error) {
+	// Bodies are left out: replaying one while the first attempt still reads it isn't safe for every
+	// GetBody, e.g. one seeking a shared file.
+	if !isIdempotent(req.Method) || hasBody(req) {
+		return d.Next.Do(req)
+	}
+
+	// Buffered so that attempts finishing after the winner never block.
+	results := make(chan hedgedResult, d.Max)
+	var cancels []context.CancelFunc
+
+	launch := func(r *http.Request, cancel context.CancelFunc) {
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := d.Next.Do(r)
+			results <- hedgedResult{attempt: attempt, resp: resp, err: err}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	launch(req.WithContext(ctx), cancel)
+	inFlight := 1
+
+	timer := time.NewTimer(d.After)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			ctx, cancel := context.WithCancel(req.Context())
+			launch(req.Clone(ctx), cancel)
+			inFlight++
+
+			if len(cancels) < d.Max {
+				timer.Reset(d.After)
+			}
+
+		case res := <-results:
+			inFlight--
+
+			if res.err != nil && inFlight > 0 {
+				cancels[res.attempt]()
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != res.attempt {
+					cancel()
+				}
+			}
+			go discardHedged(results, inFlight)
+
+			if res.err != nil {
+				cancels[res.attempt]()
+				return nil, res.err
+			}
+
+			if res.resp.Body == nil {
+				// Nothing left to read, the winner's context can go too.
+				cancels[res.attempt]()
+				return res.resp, nil
+			}
+
+			// The winner's context must outlive Do, it is released once its body is closed.
+			res.resp.Body = &cancelOnClose{ReadCloser: res.resp.Body, cancel: cancels[res.attempt]}
+			return res.resp, nil
+		}
+	}
+}
+
+// discardHedged releases the responses the n canceled attempts still in flight may return.
+func discardHedged(results <-chan hedgedResult, n int) {
+	for range n {
+		if res := <-results; res.resp != nil {
+			DrainAndClose(res.resp)
+		}
+	}
+}
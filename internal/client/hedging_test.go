@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithHedging(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+
+	// The first attempt hangs until canceled, the following ones answer right away.
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			<-req.Context().Done()
+			close(canceled)
+			return nil, req.Context().Err()
+		}
+		return stringResponse(http.StatusOK, "fast"), nil
+	})
+
+	c, err := New(WithCustomDoer(doer), WithHedging(10*time.Millisecond, 3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "https://example.com", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "fast" {
+		t.Errorf("Got body %q, want %q", body, "fast")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Made %d calls, want 2", got)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the slow attempt to be canceled")
+	}
+}
+
+func TestClient_WithHedging_NotHedged(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		delay  time.Duration
+	}{
+		{name: "fast response", method: http.MethodGet},
+		{name: "non-idempotent method", method: http.MethodPost, delay: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				calls.Add(1)
+				time.Sleep(tt.delay)
+				return stringResponse(http.StatusOK, ""), nil
+			})
+
+			c, _ := New(WithCustomDoer(doer), WithHedging(5*time.Millisecond, 3))
+			resp, err := c.do(context.Background(), tt.method, "https://example.com", nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := calls.Load(); got != 1 {
+				t.Errorf("Made %d calls, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHedgingDoer_NilBody(t *testing.T) {
+	d := &hedgingDoer{
+		Next: doerFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNoContent}, nil
+		}),
+		After: time.Hour,
+		Max:   2,
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	resp, err := d.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Body != nil {
+		t.Errorf("Got body %v, want the nil one returned as is", resp.Body)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// hostGuardTransport rejects requests whose target host is not permitted.
+// It runs for every hop, so redirects to a forbidden host are rejected as well.
+type hostGuardTransport struct {
+	Next    http.RoundTripper
+	Allowed []string
+	Blocked []string
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+// It validates the request host before delegating to the next transport.
+func (t *hostGuardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkHost(req.URL.Hostname(), t.Allowed, t.Blocked); err != nil {
+		return nil, err
+	}
+
+	return t.next().RoundTrip(req)
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *hostGuardTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// hostGuardDoer rejects requests whose target host is not permitted before handing them to a custom
+// Doer, which runs without the transport chain. Redirects followed by the Doer itself aren't seen.
+type hostGuardDoer struct {
+	Next    Doer
+	Allowed []string
+	Blocked []string
+}
+
+// Do implements the Doer interface.
+func (d *hostGuardDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := checkHost(req.URL.Hostname(), d.Allowed, d.Blocked); err != nil {
+		return nil, err
+	}
+
+	return d.Next.Do(req)
+}
+
+// checkHost validates host against the allow and block lists.
+// The blocklist wins over the allowlist, and an empty allowlist allows every host.
+func checkHost(host string, allowed, blocked []string) error {
+	for _, pattern := range blocked {
+		if matchHost(host, pattern) {
+			return fmt.Errorf("%w: %s is blocked", ErrHostNotAllowed, host)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, pattern := range allowed {
+		if matchHost(host, pattern) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not in the allowlist", ErrHostNotAllowed, host)
+}
+
+// matchHost reports whether host matches pattern, case-insensitively.
+// A pattern of the form "*.example.com" matches any subdomain of example.com, but not example.com itself.
+// A "*" anywhere else is compared literally, so "*example.com" doesn't match "evilexample.com".
+func matchHost(host, pattern string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+
+	return host == pattern
+}
+
+// validHostPattern reports whether pattern is a plain host or uses the "*." wildcard form.
+func validHostPattern(pattern string) bool {
+	return !strings.Contains(strings.TrimPrefix(pattern, "*."), "*")
+}
+
+// denyPrivateNetworks is a net.Dialer control function that refuses connections to
+// loopback, private, link-local and unspecified addresses.
+// Because it inspects the address actually being dialed, after DNS resolution,
+// it cannot be bypassed by a hostname that later rebinds to an internal IP.
+func denyPrivateNetworks(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, address)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || isPrivateIP(ip) {
+		return fmt.Errorf("%w: %s is a private network address", ErrHostNotAllowed, host)
+	}
+
+	return nil
+}
+
+// isPrivateIP reports whether ip belongs to a range that should not be reachable from untrusted input.
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified()
+}
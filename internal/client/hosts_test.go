@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		allowed []string
+		blocked []string
+		wantErr bool
+	}{
+		{name: "no lists", host: "example.com"},
+		{name: "allowed exact", host: "example.com", allowed: []string{"example.com"}},
+		{name: "allowed case-insensitive", host: "EXAMPLE.com", allowed: []string{"example.COM"}},
+		{name: "not in allowlist", host: "evil.com", allowed: []string{"example.com"}, wantErr: true},
+		{name: "wildcard subdomain", host: "api.example.com", allowed: []string{"*.example.com"}},
+		{name: "wildcard excludes apex", host: "example.com", allowed: []string{"*.example.com"}, wantErr: true},
+		{name: "wildcard excludes lookalike", host: "badexample.com", allowed: []string{"*.example.com"}, wantErr: true},
+		{name: "wildcard without dot is literal", host: "evilexample.com", allowed: []string{"*example.com"}, wantErr: true},
+		{name: "wildcard deep subdomain", host: "a.b.example.com", allowed: []string{"*.example.com"}},
+		{name: "blocked", host: "internal.local", blocked: []string{"internal.local"}, wantErr: true},
+		{
+			name:    "blocklist wins",
+			host:    "admin.example.com",
+			allowed: []string{"*.example.com"},
+			blocked: []string{"admin.example.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHost(tt.host, tt.allowed, tt.blocked)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrHostNotAllowed) {
+					t.Errorf("Expected ErrHostNotAllowed, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDenyPrivateNetworks(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: "127.0.0.1:80", wantErr: true},
+		{address: "[::1]:443", wantErr: true},
+		{address: "10.1.2.3:80", wantErr: true},
+		{address: "192.168.0.10:80", wantErr: true},
+		{address: "169.254.169.254:80", wantErr: true},
+		{address: "0.0.0.0:80", wantErr: true},
+		{address: "8.8.8.8:53"},
+		{address: "[2001:4860:4860::8888]:443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := denyPrivateNetworks("tcp", tt.address, nil)
+
+			if tt.wantErr != (err != nil) {
+				t.Errorf("denyPrivateNetworks(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_HostGuard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+	}{
+		{name: "private network blocked", opts: []ClientOption{WithBlockPrivateNetworks()}},
+		{name: "host not allowed", opts: []ClientOption{WithAllowedHosts("example.com")}},
+		{name: "host blocked", opts: []ClientOption{WithBlockedHosts("127.0.0.1")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err = c.Get(context.Background(), srv.URL, nil)
+			if !errors.Is(err, ErrHostNotAllowed) {
+				t.Errorf("Expected ErrHostNotAllowed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_HostGuard_CustomDoer(t *testing.T) {
+	var sent bool
+	doer := doerFunc(func(*http.Request) (*http.Response, error) {
+		sent = true
+		return stringResponse(http.StatusOK, ""), nil
+	})
+
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		url     string
+		wantErr bool
+	}{
+		{name: "allowed", opts: []ClientOption{WithAllowedHosts("example.com")}, url: "https://example.com"},
+		{name: "not allowed", opts: []ClientOption{WithAllowedHosts("example.com")}, url: "https://evil.com", wantErr: true},
+		{name: "blocked", opts: []ClientOption{WithBlockedHosts("internal.local")}, url: "https://internal.local", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent = false
+
+			c, err := New(append(tt.opts, WithCustomDoer(doer))...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err = c.Get(context.Background(), tt.url, nil)
+			if tt.wantErr {
+				if !errors.Is(err, ErrHostNotAllowed) {
+					t.Errorf("Expected ErrHostNotAllowed, got %v", err)
+				}
+				if sent {
+					t.Errorf("Expected the request not to reach the Doer")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("private network blocking rejected", func(t *testing.T) {
+		if _, err := New(WithCustomDoer(doer), WithBlockPrivateNetworks()); err == nil {
+			t.Errorf("Expected an error, private network blocking would be silently dropped")
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/glwbr/brisa/pkg/errors"
+)
+
+// jsonSnippetRadius is how many body bytes are kept on each side of a decode error offset.
+const jsonSnippetRadius = 40
+
+// JSONDecodeError is returned by DecodeJSON when the response body is not valid JSON or doesn't
+// match the target type. It tells where in the body decoding failed.
+type JSONDecodeError struct {
+	// Offset is the byte offset in the body where the error was detected.
+	Offset int64
+
+	// Snippet is the part of the body surrounding Offset, bounded to a few dozen bytes on each side.
+	Snippet string
+
+	// Err is the underlying *json.SyntaxError or *json.UnmarshalTypeError.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *JSONDecodeError) Error() string {
+	return fmt.Sprintf("invalid JSON at offset %d near %q: %v", e.Offset, e.Snippet, e.Err)
+}
+
+// Unwrap returns the underlying encoding/json error.
+func (e *JSONDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON reads the response body into v and closes it.
+// Malformed JSON and type mismatches are reported as a *JSONDecodeError pointing at the
+// offending part of the body.
+func DecodeJSON(resp *http.Response, v any) error {
+	if resp == nil || resp.Body == nil {
+		return errors.New("no response body to decode")
+	}
+	defer resp.Body.Close()
+
+	return JSONDecoder{}.Decode(resp.Body, v)
+}
+
+// GetJSON sends a GET request and decodes the JSON response body into v.
+// It sends "Accept: application/json" unless opts sets an Accept header.
+// Error statuses are returned as errors, like Get does, without decoding the body.
+func (c *Client) GetJSON(ctx context.Context, path string, opts *RequestConfig, v any) error {
+	if opts == nil || !hasHeader(opts.Headers, "Accept") {
+		opts = withRequestHeader(opts, "Accept", "application/json")
+	}
+
+	resp, err := c.Get(ctx, path, opts)
+	if err != nil {
+		DrainAndClose(resp)
+		return err
+	}
+
+	return DecodeJSON(resp, v)
+}
+
+// PostJSON sends in, encoded as JSON, in a POST request and decodes the JSON response body into out.
+// out may be nil when the response body doesn't matter. The request body replaces any body set in
+// opts; Content-Type and Accept default to application/json unless opts sets them.
+func (c *Client) PostJSON(ctx context.Context, path string, in any, opts *RequestConfig, out any) error {
+	return c.sendJSON(ctx, http.MethodPost, path, in, opts, out)
+}
+
+// DeleteJSON is like PostJSON for a DELETE request, for APIs expecting a JSON body on DELETE.
+func (c *Client) DeleteJSON(ctx context.Context, path string, in any, opts *RequestConfig, out any) error {
+	return c.sendJSON(ctx, http.MethodDelete, path, in, opts, out)
+}
+
+// PostJSONStream sends v, encoded as JSON, in a POST request, streaming the body as it is encoded
+// instead of marshaling it in memory first. This suits large payloads, e.g. for bulk APIs: when v is
+// a slice or an array, its elements are encoded one at a time, so only one of them is held encoded
+// in memory at once; other values are encoded as a whole, like with PostJSON. The body
+// has no known length, so it is sent chunked unless WithBufferUnknownLength applies, and can't be
+// replayed for retries or redirects. An encoding error fails the call and is returned wrapped, even
+// when a response was received.
+//
+// The body set in opts is replaced, and Content-Type defaults to application/json unless opts sets
+// it. Unlike PostJSON, the response is returned as is.
+func (c *Client) PostJSONStream(ctx context.Context, path string, v any, opts *RequestConfig) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	encoded := make(chan error, 1)
+	go func() {
+		err := encodeJSONStream(pw, v)
+		pw.CloseWithError(err)
+		encoded <- err
+	}()
+
+	// Work on a copy, leaving the caller's config untouched.
+	cfg := RequestConfig{}
+	if opts != nil {
+		cfg = *opts
+	}
+	cfg.Body, cfg.BodyBytes, cfg.GetBody = pr, nil, nil
+	opts = &cfg
+
+	if !hasHeader(opts.Headers, "Content-Type") {
+		opts = withRequestHeader(opts, "Content-Type", "application/json")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, opts)
+
+	// The response may come before the whole body was read: stop the encoder if it's still writing,
+	// and fail the call if it didn't finish, unless the body was closed first.
+	pr.Close()
+	if encErr := <-encoded; encErr != nil && encErr != io.ErrClosedPipe {
+		DrainAndClose(resp)
+		return nil, errors.Wrap(encErr, "failed to encode JSON request body")
+	}
+
+	return resp, err
+}
+
+// encodeJSONStream writes v as JSON to w, element by element for slices and arrays.
+func encodeJSONStream(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	if _, ok := v.(json.Marshaler); ok || !rv.IsValid() ||
+		(rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) ||
+		rv.Type().Elem().Kind() == reflect.Uint8 || (rv.Kind() == reflect.Slice && rv.IsNil()) {
+		// Byte slices are base64 strings and nil slices null: leave them to encoding/json.
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := range rv.Len() {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		elem, err := json.Marshal(rv.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(elem); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// sendJSON sends in as a JSON request body and decodes the response into out, if not nil.
+func (c *Client) sendJSON(ctx context.Context, method, path string, in any, opts *RequestConfig, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode JSON request body")
+	}
+
+	// Work on a copy, leaving the caller's config untouched.
+	cfg := RequestConfig{}
+	if opts != nil {
+		cfg = *opts
+	}
+	cfg.Body, cfg.BodyBytes, cfg.GetBody = nil, body, nil
+	opts = &cfg
+
+	for _, key := range []string{"Content-Type", "Accept"} {
+		if !hasHeader(opts.Headers, key) {
+			opts = withRequestHeader(opts, key, "application/json")
+		}
+	}
+
+	resp, err := c.do(ctx, method, path, opts)
+	if err != nil {
+		DrainAndClose(resp)
+		return err
+	}
+
+	if out == nil {
+		DrainAndClose(resp)
+		return nil
+	}
+
+	return DecodeJSON(resp, out)
+}
+
+// decodeError locates err in body when encoding/json reports an offset for it.
+func decodeError(body []byte, err error) error {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return errors.Wrap(err, "failed to decode JSON")
+	}
+
+	return &JSONDecodeError{
+		Offset:  offset,
+		Snippet: jsonSnippet(body, offset),
+		Err:     err,
+	}
+}
+
+// jsonSnippet returns the bytes of body within jsonSnippetRadius of offset.
+func jsonSnippet(body []byte, offset int64) string {
+	start := max(offset-jsonSnippetRadius, 0)
+	end := min(offset+jsonSnippetRadius, int64(len(body)))
+	if start > end {
+		start = end
+	}
+	return string(body[start:end])
+}
@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		wantOffset  int64
+		wantSnippet string
+	}{
+		{name: "valid", body: `{"name":"a","count":2}`},
+		{name: "syntax error", body: `{"name":"a",,"count":2}`, wantErr: true, wantOffset: 13, wantSnippet: `{"name":"a",,"count":2}`},
+		{name: "type mismatch", body: `{"name":"a","count":"two"}`, wantErr: true, wantOffset: 25, wantSnippet: `{"name":"a","count":"two"}`},
+		{
+			name:        "bounded snippet",
+			body:        `{"pad":"` + strings.Repeat("x", 100) + `",}` + strings.Repeat(" ", 100),
+			wantErr:     true,
+			wantOffset:  111,
+			wantSnippet: strings.Repeat("x", 37) + `",}` + strings.Repeat(" ", 40),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got item
+			err := DecodeJSON(stringResponse(http.StatusOK, tt.body), &got)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if got != (item{Name: "a", Count: 2}) {
+					t.Errorf("Got %+v", got)
+				}
+				return
+			}
+
+			var decodeErr *JSONDecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Expected a *JSONDecodeError, got %T: %v", err, err)
+			}
+			if decodeErr.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", decodeErr.Offset, tt.wantOffset)
+			}
+			if decodeErr.Snippet != tt.wantSnippet {
+				t.Errorf("Got snippet %q, want %q", decodeErr.Snippet, tt.wantSnippet)
+			}
+
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+				t.Errorf("Expected the encoding/json error in the chain, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_GetJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ClientOption
+		req        *RequestConfig
+		wantAccept string
+	}{
+		{name: "default", wantAccept: "application/json"},
+		{name: "overrides client default", opts: []ClientOption{WithAccept("text/plain")}, wantAccept: "application/json"},
+		{name: "per-request wins", req: &RequestConfig{Headers: map[string]string{"accept": "application/vnd.api+json"}}, wantAccept: "application/vnd.api+json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				accept = req.Header.Get("Accept")
+				return stringResponse(http.StatusOK, `{"name":"a"}`), nil
+			})
+
+			c, _ := New(append(tt.opts, WithCustomDoer(doer))...)
+
+			var got struct{ Name string }
+			if err := c.GetJSON(context.Background(), "https://example.com", tt.req, &got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Name != "a" {
+				t.Errorf("Got %+v", got)
+			}
+			if accept != tt.wantAccept {
+				t.Errorf("Accept = %q, want %q", accept, tt.wantAccept)
+			}
+		})
+	}
+}
+
+func TestClient_SendJSON(t *testing.T) {
+	type payload struct {
+		Query string `json:"query"`
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		send   func(c *Client, opts *RequestConfig, out any) error
+	}{
+		{
+			name:   "PostJSON",
+			method: http.MethodPost,
+			send: func(c *Client, opts *RequestConfig, out any) error {
+				return c.PostJSON(context.Background(), "https://example.com/items", payload{Query: "q"}, opts, out)
+			},
+		},
+		{
+			name:   "DeleteJSON",
+			method: http.MethodDelete,
+			send: func(c *Client, opts *RequestConfig, out any) error {
+				return c.DeleteJSON(context.Background(), "https://example.com/index", payload{Query: "q"}, opts, out)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, contentType, body string
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				method, contentType = req.Method, req.Header.Get("Content-Type")
+				b, _ := io.ReadAll(req.Body)
+				body = string(b)
+				return stringResponse(http.StatusOK, `{"acknowledged":true}`), nil
+			})
+			c, _ := New(WithCustomDoer(doer))
+
+			opts := &RequestConfig{Headers: map[string]string{"X-Trace": "1"}}
+			var out struct{ Acknowledged bool }
+			if err := tt.send(c, opts, &out); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if method != tt.method {
+				t.Errorf("Method = %s, want %s", method, tt.method)
+			}
+			if body != `{"query":"q"}` {
+				t.Errorf("Got body %q", body)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			if !out.Acknowledged {
+				t.Errorf("Expected the response to be decoded")
+			}
+			if len(opts.Headers) != 1 || opts.BodyBytes != nil {
+				t.Errorf("Expected the caller's config to be left untouched, got %+v", opts)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteWithBody(t *testing.T) {
+	var body string
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+		return stringResponse(http.StatusOK, ""), nil
+	})
+	c, _ := New(WithCustomDoer(doer))
+
+	if _, err := c.Delete(context.Background(), "https://example.com/index", &RequestConfig{Body: strings.NewReader("ids")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if body != "ids" {
+		t.Errorf("Got body %q, want %q", body, "ids")
+	}
+}
+
+// failingJSON always fails to encode.
+type failingJSON struct{}
+
+func (failingJSON) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("unsupported value")
+}
+
+func TestClient_PostJSONStream(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	var got []item
+	var chunked bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunked = len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked"
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := New(WithBaseURL(srv.URL))
+
+	items := make([]item, 1000)
+	for i := range items {
+		items[i].ID = i
+	}
+
+	resp, err := c.PostJSONStream(context.Background(), "/bulk", items, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(got) != len(items) || got[999].ID != 999 {
+		t.Errorf("Server decoded %d items, want %d", len(got), len(items))
+	}
+	if !chunked {
+		t.Errorf("Expected the body to be streamed chunked")
+	}
+
+	t.Run("encoding error", func(t *testing.T) {
+		_, err := c.PostJSONStream(context.Background(), "/bulk", []any{1, failingJSON{}}, nil)
+		if err == nil || !strings.Contains(err.Error(), "failed to encode JSON request body") {
+			t.Errorf("Got error %v, want the encoding error", err)
+		}
+	})
+
+	t.Run("encoding error despite a response", func(t *testing.T) {
+		// A server answering whatever the body, truncated or not.
+		doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+			io.Copy(io.Discard, req.Body)
+			return stringResponse(http.StatusOK, ""), nil
+		})
+		c, _ := New(WithCustomDoer(doer))
+
+		resp, err := c.PostJSONStream(context.Background(), "https://example.com/bulk", []any{1, failingJSON{}}, nil)
+		if err == nil || !strings.Contains(err.Error(), "failed to encode JSON request body") {
+			t.Errorf("Got error %v, want the encoding error", err)
+		}
+		if resp != nil {
+			t.Errorf("Got a response, want none")
+		}
+	})
+}
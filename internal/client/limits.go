@@ -0,0 +1,106 @@
+package client
+
+import (
+	"io"
+	"net/http"
+)
+
+// limitedBody wraps a response body and fails with ErrResponseTooLarge once more than
+// the allowed number of bytes would be read, instead of silently truncating it.
+type limitedBody struct {
+	rc        io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+// limitBody wraps rc so reads fail past limit bytes. A limit <= 0 returns rc unchanged.
+func limitBody(rc io.ReadCloser, limit int64) io.ReadCloser {
+	if rc == nil || limit <= 0 {
+		return rc
+	}
+	return &limitedBody{rc: rc, remaining: limit}
+}
+
+// responseLimit returns the response size limit of a request made with opts: its MaxResponseSize
+// when set, the client's WithMaxResponseSize otherwise.
+func (c *Client) responseLimit(opts *RequestConfig) int64 {
+	if opts.MaxResponseSize != 0 {
+		return opts.MaxResponseSize
+	}
+	return c.config.MaxResponseSize
+}
+
+// Read implements io.Reader.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+
+	if b.remaining <= 0 {
+		// The limit is consumed: probe for a single extra byte to tell EOF from an oversized body.
+		var probe [1]byte
+		n, err := b.rc.Read(probe[:])
+		if n > 0 {
+			b.exceeded = true
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.rc.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *limitedBody) Close() error {
+	return b.rc.Close()
+}
+
+// limitedRequestBody wraps a request body and fails with ErrRequestTooLarge once more than the allowed
+// number of bytes would be read by the transport, aborting the request.
+type limitedRequestBody struct {
+	rc        io.ReadCloser
+	remaining int64
+}
+
+// limitRequestBody makes the body of req, and the copies returned by its GetBody, fail past limit bytes.
+func limitRequestBody(req *http.Request, limit int64) {
+	req.Body = &limitedRequestBody{rc: req.Body, remaining: limit}
+
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &limitedRequestBody{rc: body, remaining: limit}, nil
+		}
+	}
+}
+
+// Read implements io.Reader.
+func (b *limitedRequestBody) Read(p []byte) (int, error) {
+	// Read one byte past the limit at most, enough to tell an oversized body without sending it.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.rc.Read(p)
+	if int64(n) > b.remaining {
+		return 0, ErrRequestTooLarge
+	}
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *limitedRequestBody) Close() error {
+	return b.rc.Close()
+}
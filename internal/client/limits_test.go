@@ -0,0 +1,267 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLimitBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limit   int64
+		want    string
+		wantErr error
+	}{
+		{name: "unlimited", body: "hello world", limit: 0, want: "hello world"},
+		{name: "under limit", body: "hello", limit: 10, want: "hello"},
+		{name: "exactly at limit", body: "hello", limit: 5, want: "hello"},
+		{name: "over limit", body: "hello world", limit: 5, wantErr: ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := limitBody(io.NopCloser(strings.NewReader(tt.body)), tt.limit)
+
+			got, err := io.ReadAll(rc)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_MaxResponseSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 1024))
+	}))
+	defer srv.Close()
+
+	c, err := New(WithMaxResponseSize(100))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestClient_MaxResponseSize_PerRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 1024))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		clientMax  int64
+		requestMax int64
+		wantErr    bool
+	}{
+		{name: "client limit", clientMax: 100, wantErr: true},
+		{name: "raised for the request", clientMax: 100, requestMax: 2048},
+		{name: "disabled for the request", clientMax: 100, requestMax: -1},
+		{name: "lowered for the request", clientMax: 4096, requestMax: 100, wantErr: true},
+		{name: "set for the request only", requestMax: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(WithMaxResponseSize(tt.clientMax))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Get(context.Background(), srv.URL, &RequestConfig{MaxResponseSize: tt.requestMax})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+				}
+				return
+			}
+			if err != nil || len(body) != 1024 {
+				t.Errorf("Read %d bytes with error %v, want the whole body", len(body), err)
+			}
+		})
+	}
+}
+
+func TestClient_MaxResponseSize_GzipBomb(t *testing.T) {
+	const (
+		limit        = 1 << 20
+		decompressed = 64 << 20
+	)
+
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	if _, err := io.CopyN(zw, zeroReader{}, decompressed); err != nil {
+		t.Fatalf("Failed to build gzip payload: %v", err)
+	}
+	zw.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(bomb.Bytes())
+	}))
+	defer srv.Close()
+
+	// Debug logging peeks at the body too, so make sure it doesn't buffer the whole stream.
+	c, err := New(WithMaxResponseSize(limit), WithDebug(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if n > limit {
+		t.Errorf("Read %d bytes, more than the %d byte limit", n, limit)
+	}
+	if int64(bomb.Len()) >= limit {
+		t.Fatalf("Compressed payload is %d bytes, test needs it below the limit", bomb.Len())
+	}
+}
+
+// zeroReader is an infinite stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestClient_MaxRequestSize(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer srv.Close()
+
+	c, err := New(WithMaxRequestSize(100))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name    string
+		body    io.Reader
+		wantErr bool
+		// wantSent is whether the server must be reached, nil when it may be, as it is for an
+		// unknown length body that is aborted while being sent.
+		wantSent *bool
+	}{
+		{name: "within limit", body: strings.NewReader(strings.Repeat("x", 100)), wantSent: boolPtr(true)},
+		{name: "known length over limit", body: strings.NewReader(strings.Repeat("x", 101)), wantErr: true, wantSent: boolPtr(false)},
+		{name: "unknown length within limit", body: io.MultiReader(strings.NewReader(strings.Repeat("x", 100))), wantSent: boolPtr(true)},
+		{name: "unknown length over limit", body: io.MultiReader(strings.NewReader(strings.Repeat("x", 1024))), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+
+			resp, err := c.Post(context.Background(), srv.URL, &RequestConfig{Body: tt.body})
+			if tt.wantErr {
+				if !errors.Is(err, ErrRequestTooLarge) {
+					t.Errorf("Expected ErrRequestTooLarge, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			} else {
+				resp.Body.Close()
+			}
+
+			if sent := hits.Load() > 0; tt.wantSent != nil && sent != *tt.wantSent {
+				t.Errorf("Got request sent %v, want %v", sent, *tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestClient_MaxRequestSize_DebugLogging(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer srv.Close()
+
+	rec := newRecordingLogger()
+	c, err := New(
+		WithMaxRequestSize(100),
+		WithDebug(true),
+		WithLogger(rec),
+		WithRetryAttempts(2),
+		WithRetryMethods(http.MethodPost),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var attempts atomic.Int32
+	getBody := func() (io.ReadCloser, error) {
+		attempts.Add(1)
+		return io.NopCloser(io.MultiReader(strings.NewReader(strings.Repeat("x", 1024)))), nil
+	}
+
+	_, err = c.Post(context.Background(), srv.URL, &RequestConfig{GetBody: getBody})
+	if !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("Expected ErrRequestTooLarge, got %v", err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("Got %d requests sent, want none", n)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("Got %d attempts, want the oversized body not to be retried", n)
+	}
+
+	var failed bool
+	for _, e := range rec.Entries() {
+		failed = failed || e.Msg == "HTTP Request failed"
+	}
+	if !failed {
+		t.Errorf("Expected the rejected request to be logged as failed")
+	}
+}
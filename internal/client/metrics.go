@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives measurements of the HTTP requests sent by the client.
+// Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// RequestStarted is called right before a request is sent.
+	RequestStarted(method, host string)
+
+	// RequestFinished is called once the response headers are received or the request failed.
+	// The status is 0 when err is not nil.
+	RequestFinished(method, host string, status int, duration time.Duration, err error)
+}
+
+// metricsTransport reports every request going through it to a MetricsRecorder.
+// It sits inside the retry layer, so each attempt is measured separately.
+type metricsTransport struct {
+	Next     http.RoundTripper
+	Recorder MetricsRecorder
+	Clock    Clock
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+// It times the round trip and reports its outcome to the recorder.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, host := req.Method, req.URL.Host
+
+	t.Recorder.RequestStarted(method, host)
+	start := t.clock().Now()
+
+	resp, err := t.next().RoundTrip(req)
+
+	var status int
+	if err == nil {
+		status = resp.StatusCode
+	}
+	t.Recorder.RequestFinished(method, host, status, t.clock().Now().Sub(start), err)
+
+	return resp, err
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *metricsTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// clock returns the Clock used to time requests, or the real clock if nil.
+func (t *metricsTransport) clock() Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return realClock{}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedRequest is a single RequestFinished call captured by fakeRecorder.
+type recordedRequest struct {
+	method, host string
+	status       int
+	err          error
+}
+
+// fakeRecorder is a MetricsRecorder that captures calls for assertions.
+type fakeRecorder struct {
+	mu       sync.Mutex
+	inFlight int
+	finished []recordedRequest
+}
+
+func (r *fakeRecorder) RequestStarted(method, host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight++
+}
+
+func (r *fakeRecorder) RequestFinished(method, host string, status int, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight--
+	r.finished = append(r.finished, recordedRequest{method: method, host: host, status: status, err: err})
+}
+
+func TestMetricsTransport(t *testing.T) {
+	rec := &fakeRecorder{}
+	failure := errors.New("connection refused")
+
+	tr := &metricsTransport{
+		Next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/fail" {
+				return nil, failure
+			}
+			return stringResponse(http.StatusCreated, ""), nil
+		}),
+		Recorder: rec,
+	}
+
+	for _, path := range []string{"/ok", "/fail"} {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://example.com"+path, nil)
+		tr.RoundTrip(req)
+	}
+
+	want := []recordedRequest{
+		{method: http.MethodPost, host: "example.com", status: http.StatusCreated},
+		{method: http.MethodPost, host: "example.com", status: 0, err: failure},
+	}
+
+	if rec.inFlight != 0 {
+		t.Errorf("In-flight count = %d, want 0", rec.inFlight)
+	}
+	if len(rec.finished) != len(want) {
+		t.Fatalf("Recorded %d requests, want %d", len(rec.finished), len(want))
+	}
+	for i := range want {
+		if rec.finished[i] != want[i] {
+			t.Errorf("Request %d = %+v, want %+v", i, rec.finished[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxTokenRefreshLeeway is how long before its expiry a token is refreshed, so that it doesn't
+	// expire while a request is in flight. Short-lived tokens are refreshed at half their lifetime.
+	maxTokenRefreshLeeway = 30 * time.Second
+
+	// maxTokenResponseSize bounds how much of a token endpoint response is read.
+	maxTokenResponseSize = 1 << 20
+)
+
+// clientCredentials fetches and caches OAuth2 access tokens with the client credentials grant.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	doer         Doer
+	now          func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+}
+
+// tokenResponse is the successful response of a token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// tokenError is the error response of a token endpoint.
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// authorize is a RequestInterceptor setting a Bearer Authorization header on req, with a token
+// fetched on first use and refreshed before it expires.
+func (c *clientCredentials) authorize(ctx context.Context, req *http.Request) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// accessToken returns the cached token, fetching a new one when it is missing or about to expire.
+// Concurrent callers wait for a single fetch.
+func (c *clientCredentials) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.refresh.IsZero() || c.now().Before(c.refresh)) {
+		return c.token, nil
+	}
+
+	tok, err := c.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch OAuth2 token: %w", err)
+	}
+
+	c.token = tok.AccessToken
+	c.refresh = time.Time{}
+	if tok.ExpiresIn > 0 {
+		lifetime := time.Duration(tok.ExpiresIn) * time.Second
+		c.refresh = c.now().Add(lifetime - min(maxTokenRefreshLeeway, lifetime/2))
+	}
+
+	return c.token, nil
+}
+
+// fetch requests a new token from the token endpoint, authenticating with HTTP Basic.
+func (c *clientCredentials) fetch(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var tokErr tokenError
+		if json.Unmarshal(body, &tokErr) == nil && tokErr.Error != "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s %s", resp.Status, tokErr.Error, tokErr.Description)
+		}
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var tok tokenResponse
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return nil, fmt.Errorf("invalid token response: missing access_token")
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", tok.TokenType)
+	}
+
+	return &tok, nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer returns a token endpoint issuing "token-<n>" tokens valid for expiresIn seconds,
+// checking the client credentials.
+func newTokenServer(t *testing.T, fetches *atomic.Int32, expiresIn int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "app" || secret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read write" {
+			t.Errorf("Unexpected token request form: %v", r.Form)
+		}
+
+		n := fetches.Add(1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+}
+
+func TestClientCredentials_Refresh(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newTokenServer(t, &fetches, 3600)
+	defer srv.Close()
+
+	now := time.Now()
+	creds := &clientCredentials{
+		tokenURL:     srv.URL,
+		clientID:     "app",
+		clientSecret: "s3cr3t",
+		scopes:       []string{"read", "write"},
+		doer:         srv.Client(),
+		now:          func() time.Time { return now },
+	}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    string
+	}{
+		{name: "first use", want: "token-1"},
+		{name: "cached", elapsed: 30 * time.Minute, want: "token-1"},
+		{name: "refreshed before expiry", elapsed: time.Hour - 20*time.Second, want: "token-2"},
+	}
+
+	start := now
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = start.Add(tt.elapsed)
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+			if err := creds.authorize(context.Background(), req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer "+tt.want {
+				t.Errorf("Got %q, want %q", got, "Bearer "+tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_WithOAuth2ClientCredentials(t *testing.T) {
+	var fetches atomic.Int32
+	tokenSrv := newTokenServer(t, &fetches, 3600)
+	defer tokenSrv.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	c, err := New(WithBaseURL(api.URL), WithOAuth2ClientCredentials(tokenSrv.URL, "app", "s3cr3t", []string{"read", "write"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 3 {
+		resp, err := c.Get(context.Background(), "/items", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("Fetched %d tokens, want 1", got)
+	}
+
+	t.Run("token fetch failure", func(t *testing.T) {
+		c, _ := New(WithBaseURL(api.URL), WithOAuth2ClientCredentials(tokenSrv.URL, "app", "wrong", nil))
+
+		_, err := c.Get(context.Background(), "/items", nil)
+		if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+			t.Errorf("Got error %v, want the token endpoint error", err)
+		}
+	})
+}
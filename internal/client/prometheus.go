@@ -0,0 +1,69 @@
+//go:build prometheus
+
+package client
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusRecorder is a MetricsRecorder exposing the client's requests as Prometheus metrics:
+//   - http_client_requests_total (counter): labels method, host, status
+//   - http_client_request_duration_seconds (histogram): labels method, host, status
+//   - http_client_in_flight (gauge): labels method, host
+//
+// The status label is the response status code, or "error" when the request failed.
+// It is only available when building with the "prometheus" tag.
+type PrometheusRecorder struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewPrometheusRecorder creates a PrometheusRecorder and registers its metrics with registerer.
+// Pass prometheus.DefaultRegisterer to expose them through the default registry.
+// Registering twice on the same registerer fails, so create one recorder and share it between clients.
+func NewPrometheusRecorder(registerer prometheus.Registerer) (*PrometheusRecorder, error) {
+	r := &PrometheusRecorder{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_requests_total",
+			Help: "Total number of HTTP requests sent, by method, host and status.",
+		}, []string{"method", "host", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Time until the response headers were received, by method, host and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "host", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_client_in_flight",
+			Help: "Number of HTTP requests currently awaiting a response, by method and host.",
+		}, []string{"method", "host"}),
+	}
+
+	for _, c := range []prometheus.Collector{r.requests, r.duration, r.inFlight} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// RequestStarted implements MetricsRecorder.
+func (r *PrometheusRecorder) RequestStarted(method, host string) {
+	r.inFlight.WithLabelValues(method, host).Inc()
+}
+
+// RequestFinished implements MetricsRecorder.
+func (r *PrometheusRecorder) RequestFinished(method, host string, status int, duration time.Duration, err error) {
+	label := "error"
+	if err == nil {
+		label = strconv.Itoa(status)
+	}
+
+	r.inFlight.WithLabelValues(method, host).Dec()
+	r.requests.WithLabelValues(method, host, label).Inc()
+	r.duration.WithLabelValues(method, host, label).Observe(duration.Seconds())
+}
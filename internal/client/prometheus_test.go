@@ -0,0 +1,51 @@
+//go:build prometheus
+
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheusRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	r, err := NewPrometheusRecorder(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r.RequestStarted(http.MethodGet, "example.com")
+	r.RequestFinished(http.MethodGet, "example.com", http.StatusOK, time.Millisecond, nil)
+	r.RequestStarted(http.MethodGet, "example.com")
+	r.RequestFinished(http.MethodGet, "example.com", 0, time.Millisecond, errors.New("boom"))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := map[string]int{}
+	for _, f := range families {
+		got[f.GetName()] = len(f.GetMetric())
+	}
+
+	want := map[string]int{
+		"http_client_requests_total":           2, // status 200 and "error"
+		"http_client_request_duration_seconds": 2,
+		"http_client_in_flight":                1,
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s has %d series, want %d", name, got[name], n)
+		}
+	}
+
+	if _, err := NewPrometheusRecorder(reg); err == nil {
+		t.Errorf("Expected registering twice to fail")
+	}
+}
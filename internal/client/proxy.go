@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// RotationStrategy selects how a proxy is picked from the pool for each request. See WithProxyRotation.
+type RotationStrategy int
+
+const (
+	// RoundRobin uses the proxies one after the other, in the order they were given.
+	RoundRobin RotationStrategy = iota
+
+	// RandomRotation picks a proxy at random for every request.
+	RandomRotation
+)
+
+// proxyRotator picks a proxy from a fixed pool for every request.
+type proxyRotator struct {
+	proxies  []*url.URL
+	strategy RotationStrategy
+	counter  atomic.Uint64
+}
+
+// Proxy returns the proxy to use for req, suitable for http.Transport.Proxy.
+func (r *proxyRotator) Proxy(*http.Request) (*url.URL, error) {
+	if r.strategy == RandomRotation {
+		return r.proxies[rand.IntN(len(r.proxies))], nil
+	}
+
+	i := r.counter.Add(1) - 1
+	return r.proxies[i%uint64(len(r.proxies))], nil
+}
+
+// parseProxyURL parses a proxy URL, which must be an absolute http, https or socks5 URL.
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", raw)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+
+	return u, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithProxyRotation(t *testing.T) {
+	var hits [2]atomic.Int32
+	var proxies []string
+	for i := range hits {
+		// An HTTP proxy receives plain HTTP requests with an absolute target URL.
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Host != "upstream.test" {
+				t.Errorf("Proxy got target %q, want %q", r.URL.Host, "upstream.test")
+			}
+			hits[i].Add(1)
+		}))
+		defer srv.Close()
+		proxies = append(proxies, srv.URL)
+	}
+
+	c, err := New(WithProxyRotation(proxies, RoundRobin))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 4 {
+		resp, err := c.Get(context.Background(), "http://upstream.test/", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		DrainAndClose(resp)
+	}
+
+	for i := range hits {
+		if got := hits[i].Load(); got != 2 {
+			t.Errorf("Proxy %d got %d requests, want 2", i, got)
+		}
+	}
+}
+
+func TestWithProxyRotation_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxies  []string
+		strategy RotationStrategy
+		wantErrs int
+		want     int
+	}{
+		{name: "valid", proxies: []string{"http://a:8080", "socks5://b:1080"}, want: 2},
+		{name: "invalid skipped", proxies: []string{"http://a:8080", "ftp://b", "c:80", "http://"}, wantErrs: 3, want: 1},
+		{name: "unknown strategy", proxies: []string{"http://a:8080"}, strategy: RotationStrategy(9), wantErrs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := buildConfig(WithProxyRotation(tt.proxies, tt.strategy))
+
+			if len(cfg.errs) != tt.wantErrs {
+				t.Errorf("Got %d errors, want %d: %v", len(cfg.errs), tt.wantErrs, cfg.errs)
+			}
+			if len(cfg.Proxies) != tt.want {
+				t.Errorf("Got %d proxies, want %d", len(cfg.Proxies), tt.want)
+			}
+		})
+	}
+}
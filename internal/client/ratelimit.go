@@ -0,0 +1,126 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitPrefixes are the header conventions understood by RateLimitInfo, in order of preference.
+var rateLimitPrefixes = []string{"X-RateLimit-", "X-Rate-Limit-", "RateLimit-"}
+
+// epochThreshold separates reset values given as a number of seconds from now from those given as
+// a Unix timestamp: no API waits for years before resetting its quota.
+const epochThreshold = 1_000_000_000
+
+// RateLimit is the rate limit state reported by a server in its response headers.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window, or -1 if not reported.
+	Limit int
+
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+
+	// Reset is when the current window ends, or the zero time if not reported.
+	Reset time.Time
+}
+
+// RateLimitInfo extracts the rate limit state from the response headers. It understands the
+// X-RateLimit-*, X-Rate-Limit-* and RateLimit-* conventions, with a reset given either as a
+// number of seconds from now or as a Unix timestamp:
+//
+//	if rl, ok := client.RateLimitInfo(resp); ok && rl.Remaining == 0 {
+//		log.Printf("rate limited until %s", rl.Reset)
+//	}
+//
+// It reports false if the response has no parsable Remaining header.
+func RateLimitInfo(resp *http.Response) (*RateLimit, bool) {
+	return rateLimitInfo(resp, time.Now())
+}
+
+// rateLimitInfo implements RateLimitInfo, resolving relative resets against now.
+func rateLimitInfo(resp *http.Response, now time.Time) (*RateLimit, bool) {
+	if resp == nil {
+		return nil, false
+	}
+
+	for _, prefix := range rateLimitPrefixes {
+		remaining, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get(prefix + "Remaining")))
+		if err != nil {
+			continue
+		}
+
+		rl := &RateLimit{Limit: -1, Remaining: remaining}
+		if limit, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get(prefix + "Limit"))); err == nil {
+			rl.Limit = limit
+		}
+		if reset, err := strconv.ParseInt(strings.TrimSpace(resp.Header.Get(prefix+"Reset")), 10, 64); err == nil && reset >= 0 {
+			if reset >= epochThreshold {
+				rl.Reset = time.Unix(reset, 0)
+			} else {
+				rl.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+
+		return rl, true
+	}
+
+	return nil, false
+}
+
+// rateLimitTransport delays requests while the server reports an exhausted rate limit.
+type rateLimitTransport struct {
+	Next  http.RoundTripper
+	Clock Clock
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clock := t.clock()
+
+	t.mu.Lock()
+	until := t.until
+	t.mu.Unlock()
+
+	if wait := until.Sub(clock.Now()); wait > 0 {
+		if err := clock.Sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.next().RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if rl, ok := rateLimitInfo(resp, clock.Now()); ok && rl.Remaining <= 0 && !rl.Reset.IsZero() {
+		t.mu.Lock()
+		if rl.Reset.After(t.until) {
+			t.until = rl.Reset
+		}
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *rateLimitTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// clock returns the Clock used for waiting, or the real clock if nil.
+func (t *rateLimitTransport) clock() Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return realClock{}
+}
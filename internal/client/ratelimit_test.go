@@ -0,0 +1,94 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitInfo(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    *RateLimit
+	}{
+		{
+			name:    "relative reset",
+			headers: map[string]string{"X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "7", "X-RateLimit-Reset": "30"},
+			want:    &RateLimit{Limit: 100, Remaining: 7, Reset: now.Add(30 * time.Second)},
+		},
+		{
+			name:    "epoch reset",
+			headers: map[string]string{"X-Rate-Limit-Remaining": "0", "X-Rate-Limit-Reset": "1704114000"},
+			want:    &RateLimit{Limit: -1, Remaining: 0, Reset: time.Unix(1704114000, 0)},
+		},
+		{
+			name:    "standard headers",
+			headers: map[string]string{"RateLimit-Limit": "10", "RateLimit-Remaining": "3"},
+			want:    &RateLimit{Limit: 10, Remaining: 3},
+		},
+		{
+			name:    "missing remaining",
+			headers: map[string]string{"X-RateLimit-Limit": "100"},
+		},
+		{
+			name:    "malformed remaining",
+			headers: map[string]string{"X-RateLimit-Remaining": "many"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := stringResponse(http.StatusOK, "")
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			got, ok := rateLimitInfo(resp, now)
+			if tt.want == nil {
+				if ok {
+					t.Errorf("Expected no rate limit info, got %+v", got)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("Expected rate limit info")
+			}
+			if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("Got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	var calls int
+	tr := &rateLimitTransport{
+		Clock: clock,
+		Next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			resp := stringResponse(http.StatusOK, "")
+			if calls == 1 {
+				resp.Header.Set("X-RateLimit-Remaining", "0")
+				resp.Header.Set("X-RateLimit-Reset", "20")
+			}
+			return resp, nil
+		}),
+	}
+
+	for range 3 {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		if _, err := tr.RoundTrip(req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 20*time.Second {
+		t.Errorf("Got sleeps %v, want a single 20s wait", clock.sleeps)
+	}
+}
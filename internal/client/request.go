@@ -1,19 +1,72 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"maps"
+	"math"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strings"
 
 	"github.com/glwbr/brisa/pkg/errors"
+	"github.com/glwbr/brisa/pkg/logger"
 )
 
 // RequestConfig contains options for customizing HTTP requests.
+//
+// A request body is provided by one of Body, BodyBytes or GetBody, checked in that order.
+// Redirects (307/308) and retries need to send the body again, which is only possible when it
+// can be rewound: *bytes.Buffer, *bytes.Reader, *strings.Reader, any io.Seeker, BodyBytes and
+// GetBody all qualify. Other readers are sent once and cannot be replayed. Copies of an io.Seeker
+// body obtained with http.Request.GetBody are independent of the body being sent when it is also an
+// io.ReaderAt, such as an *os.File; otherwise GetBody rewinds the body itself.
+// The body is sent whatever the method: Post and Delete are the helpers meant to carry one, while
+// GET and HEAD requests with a body are rejected by many servers.
 type RequestConfig struct {
 	Params  url.Values
 	Body    io.Reader
 	Headers map[string]string
+
+	// BodyBytes is an in-memory body, used when Body is nil.
+	BodyBytes []byte
+
+	// GetBody returns a fresh copy of the body each time it is called.
+	// It is set as http.Request.GetBody and, when Body and BodyBytes are nil, also provides the initial body.
+	GetBody func() (io.ReadCloser, error)
+
+	// ExpectContinue sends "Expect: 100-continue" so the server can reject the request
+	// before the body is transmitted. See WithExpectContinue.
+	ExpectContinue bool
+
+	// Trace, when set, receives the timings of the request (DNS, connect, TLS, first byte, total).
+	Trace *RequestTrace
+
+	// PathSegments are appended to the request path, each one escaped as a single segment: reserved
+	// characters such as "?", "#", "/" or spaces are taken literally rather than as URL syntax.
+	// E.g. a path of "users" with segments "john doe" and "a/b" requests "users/john%20doe/a%2Fb".
+	PathSegments []string
+
+	// RawQuery, when set, is used verbatim as the query string of the request, in place of Params,
+	// the client's default query parameters and any query in the path. Unlike Params, whose keys are
+	// sorted when encoded, it keeps the parameters in the given order, as required by some signature
+	// schemes (OAuth1, HMAC-signed APIs). The tradeoff is that nothing is escaped for the caller:
+	// values must already be percent-encoded, e.g. with url.QueryEscape.
+	RawQuery string
+
+	// RetryAttempts overrides the client's WithRetryAttempts for this request when set: a critical call
+	// can be retried more, and 0 disables retries. Negative values are treated as 0.
+	RetryAttempts *int
+
+	// MaxResponseSize overrides the client's WithMaxResponseSize for this request: a positive value
+	// is the limit to apply, a negative one disables it. Zero keeps the client's limit.
+	MaxResponseSize int64
+
+	// Encoder overrides the client's WithDefaultEncoder for the value sent with Send, e.g. to send a
+	// form to an API otherwise spoken to in JSON.
+	Encoder Encoder
 }
 
 // Get sends an HTTP GET request to the specified path or URL.
@@ -38,59 +91,799 @@ func (c *Client) Post(ctx context.Context, path string, opts *RequestConfig) (*h
 	return c.do(ctx, http.MethodPost, path, opts)
 }
 
+// Delete sends an HTTP DELETE request to the specified path or URL.
+// DELETE requests usually have no body, but one given in opts is sent as is, for APIs that expect it
+// (e.g. Elasticsearch). See also DeleteJSON.
+func (c *Client) Delete(ctx context.Context, path string, opts *RequestConfig) (*http.Response, error) {
+	return c.do(ctx, http.MethodDelete, path, opts)
+}
+
+// Head sends an HTTP HEAD request to the specified path or URL.
+func (c *Client) Head(ctx context.Context, path string, opts *RequestConfig) (*http.Response, error) {
+	return c.do(ctx, http.MethodHead, path, opts)
+}
+
+// HeadHeaders sends a HEAD request and returns the response headers, e.g. to check Content-Length,
+// Last-Modified or ETag, the response itself being closed. Statuses rejected by the status validator
+// are returned as an *HTTPError, along with the headers.
+func (c *Client) HeadHeaders(ctx context.Context, path string, opts *RequestConfig) (http.Header, error) {
+	resp, err := c.Head(ctx, path, opts)
+	if resp == nil {
+		return nil, err
+	}
+
+	DrainAndClose(resp)
+	return resp.Header, err
+}
+
+// Exists sends a HEAD request and reports whether the resource exists: true when the status is
+// accepted by the status validator (see WithStatusValidator), false for 404 Not Found. Any other
+// status, as well as transport failures, is returned as an error.
+// With the default validator and redirects being followed, that is true for 2xx.
+func (c *Client) Exists(ctx context.Context, path string, opts *RequestConfig) (bool, error) {
+	resp, err := c.Head(ctx, path, opts)
+	if resp != nil {
+		DrainAndClose(resp)
+		if resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Ping checks that the server is up by sending a GET request to the health path set with
+// WithHealthPath, relative to the base URL. It returns nil when the status is accepted by the status
+// validator, which with the default one and redirects being followed means 2xx, so servers with
+// non-standard health statuses can be accommodated with WithStatusValidator.
+// It fails without sending anything when the client has no base URL or health path.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.baseURL == nil && c.baseURLFunc == nil {
+		return errors.New("ping requires a base URL")
+	}
+	if c.config.HealthPath == "" {
+		return errors.New("ping requires a health path, see WithHealthPath")
+	}
+
+	resp, err := c.Get(ctx, c.config.HealthPath, nil)
+	DrainAndClose(resp)
+	if err != nil {
+		return errors.Wrap(err, "health check failed")
+	}
+
+	return nil
+}
+
 // do is the core method for executing HTTP requests with the configured client.
 func (c *Client) do(ctx context.Context, method, urlOrPath string, opts *RequestConfig) (*http.Response, error) {
+	return c.withDeadline(ctx, func(ctx context.Context) (*http.Response, error) {
+		return c.doRequest(ctx, method, urlOrPath, opts)
+	})
+}
+
+// Do sends a request built by the caller, e.g. by another library, through the client.
+//
+// Unlike Get, Post and the other methods, which build the request from a path and a RequestConfig,
+// req is sent as is: its URL is not resolved against the base URL nor given the default query
+// parameters, and its body and headers are left untouched. It still gets everything else the client
+// does: request interceptors, the transport chain (default headers, retries, logging, metrics...),
+// the client timeouts, the response checks (transforms, status validator, body interceptors and
+// validators), with the same errors as the other methods, and WithCaptureOnError.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req == nil || req.URL == nil {
+		return nil, errors.New("invalid request: missing URL")
+	}
+
+	return c.withDeadline(req.Context(), func(ctx context.Context) (*http.Response, error) {
+		if ctx != req.Context() {
+			req = req.WithContext(ctx)
+		}
+
+		resp, err := c.roundTrip(req, &RequestConfig{})
+		if err != nil {
+			c.capture(req, resp, err)
+		}
+		return resp, err
+	})
+}
+
+// withDeadline runs send with ctx bounded by the client timeouts, if any. The deadline holds until
+// the body of the returned response is closed.
+func (c *Client) withDeadline(ctx context.Context, send func(ctx context.Context) (*http.Response, error)) (*http.Response, error) {
+	if c.config.ContextTimeout <= 0 && c.config.MinRequestTimeout <= 0 {
+		return send(ctx)
+	}
+
+	ctx, cancel := c.withTimeouts(ctx)
+	resp, err := send(ctx)
+	if resp == nil || resp.Body == nil {
+		cancel()
+		return resp, err
+	}
+
+	// The deadline must hold while the body is read, release it only once it is closed.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, err
+}
+
+// withTimeouts applies the context timeout, then the minimum request timeout, to ctx.
+func (c *Client) withTimeouts(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancel := context.CancelFunc(func() {})
+	if d := c.config.ContextTimeout; d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+	}
+
+	if c.config.MinRequestTimeout <= 0 {
+		return ctx, cancel
+	}
+
+	ctx, cancelMin := c.withMinTimeout(ctx)
+	return ctx, func() {
+		cancelMin()
+		cancel()
+	}
+}
+
+// withMinTimeout applies the minimum request timeout to ctx when it has no deadline, and warns
+// about deadlines shorter than it.
+func (c *Client) withMinTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	minTimeout := c.config.MinRequestTimeout
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithTimeout(ctx, minTimeout)
+	}
+
+	if remaining := deadline.Sub(c.config.Clock.Now()); remaining < minTimeout {
+		contextLogger(c.logger, ctx).Warn("Request deadline is shorter than the minimum request timeout",
+			"remaining", remaining.String(), "min_timeout", minTimeout.String())
+	}
+	return ctx, func() {}
+}
+
+// cancelOnClose is a response body releasing its request context when closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doRequest builds and sends the request, then checks the response.
+func (c *Client) doRequest(ctx context.Context, method, urlOrPath string, opts *RequestConfig) (*http.Response, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
 	if opts == nil {
 		opts = &RequestConfig{}
 	}
 
-	u, err := c.resolveURL(urlOrPath, opts.Params)
+	req, err := c.newRequest(ctx, method, urlOrPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.roundTrip(req, opts)
+	if err == nil && c.config.FollowCreated && isCreated(req, resp) {
+		req, resp, err = c.fetchCreated(ctx, req, resp, opts)
+	}
+	if err != nil {
+		c.capture(req, resp, err)
+	}
+	return resp, err
+}
+
+// isCreated reports whether resp, answering req, points to a created resource to fetch, see
+// WithFollowCreated.
+func isCreated(req *http.Request, resp *http.Response) bool {
+	return req.Method != http.MethodGet && resp.StatusCode == http.StatusCreated && resp.Header.Get("Location") != ""
+}
+
+// fetchCreated discards resp, a 201 answering req, and sends a GET request for its Location
+// instead, returning that request and its response.
+func (c *Client) fetchCreated(ctx context.Context, req *http.Request, resp *http.Response, opts *RequestConfig) (*http.Request, *http.Response, error) {
+	DrainAndClose(resp)
+
+	loc, err := req.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return req, nil, errors.Wrap(err, "invalid Location of created resource")
+	}
+
+	headers := maps.Clone(opts.Headers)
+	maps.DeleteFunc(headers, func(k, _ string) bool { return http.CanonicalHeaderKey(k) == "Content-Type" })
+
+	fetchOpts := &RequestConfig{Headers: headers, MaxResponseSize: opts.MaxResponseSize, Trace: opts.Trace}
+	fetch, err := c.newRequest(ctx, http.MethodGet, loc.String(), fetchOpts)
+	if err != nil {
+		return req, nil, err
+	}
+
+	resp, err = c.roundTrip(fetch, fetchOpts)
+	return fetch, resp, err
+}
+
+// NewRequest builds the request Get, Post and the other methods would send, without sending it, e.g.
+// to inspect or sign it first. The URL is resolved against the base URL with the default and
+// per-request query parameters, the body and headers of opts are set, and so are the default headers
+// the transport chain would add. The request can then be sent with Do.
+//
+// Settings applied once the response is received, RequestConfig.Trace completion and
+// MaxResponseSize, are not carried by the request: Do applies the client's defaults.
+func (c *Client) NewRequest(ctx context.Context, method, path string, opts *RequestConfig) (*http.Request, error) {
+	if opts == nil {
+		opts = &RequestConfig{}
+	}
+
+	req, err := c.newRequest(ctx, method, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	applyDefaultHeaders(req, c.config.Headers, c.config.DynamicUserAgent)
+	return req, nil
+}
+
+// newRequest builds the request for method and urlOrPath from opts, up to the default headers which
+// are left to the transport chain.
+func (c *Client) newRequest(ctx context.Context, method, urlOrPath string, opts *RequestConfig) (*http.Request, error) {
+	u, err := c.resolveURL(ctx, urlOrPath, opts.Params)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to resolve URL")
 	}
+	appendPathSegments(u, opts.PathSegments)
+	if opts.RawQuery != "" {
+		u.RawQuery = opts.RawQuery
+	}
+	if c.config.LogResolvedURL {
+		contextLogger(c.logger, ctx).WithFields(map[string]any{
+			"method": method,
+			"url":    u.Redacted(),
+		}).Info("Resolved request URL")
+	}
+
+	if opts.Trace != nil {
+		ctx = httptrace.WithClientTrace(ctx, opts.Trace.clientTrace(c.config.Clock))
+	}
+	if opts.RetryAttempts != nil {
+		ctx = context.WithValue(ctx, retryAttemptsKey{}, *opts.RetryAttempts)
+	}
+	if c.config.ConnTrace {
+		ctx = httptrace.WithClientTrace(ctx, connTrace(contextLogger(c.logger, ctx), u.Host))
+	}
+
+	body, getBody, err := requestBody(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get request body")
+	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), opts.Body)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create request")
 	}
 
+	if getBody != nil {
+		req.GetBody = getBody
+	}
+
+	// http.NewRequest only knows the length of in-memory readers, other seekable bodies such as files
+	// would otherwise be sent chunked.
+	if req.ContentLength == 0 && hasBody(req) {
+		if n, ok := remainingLength(opts.Body); ok {
+			req.ContentLength = n
+		}
+	}
+
+	switch {
+	case c.config.ForceChunked && hasBody(req):
+		req.ContentLength = -1
+	case c.config.BufferBodySize > 0 && req.ContentLength == 0 && hasBody(req):
+		if err := bufferBody(req, c.config.BufferBodySize); err != nil {
+			return nil, errors.Wrap(err, "failed to buffer request body")
+		}
+	}
+
+	// Turn URL userinfo into Basic auth here rather than leaving it to http.Client, so it also
+	// works with a custom Doer and is never sent twice.
+	if user := req.URL.User; user != nil {
+		if !hasHeader(opts.Headers, "Authorization") && !hasHeader(c.config.Headers, "Authorization") {
+			password, _ := user.Password()
+			req.SetBasicAuth(user.Username(), password)
+		}
+		req.URL.User = nil
+	}
+
 	// Apply request-specific headers
 	for k, v := range opts.Headers {
 		req.Header.Set(k, v)
 	}
 
+	if c.config.AutoContentType && req.Header.Get("Content-Type") == "" && c.config.Headers["Content-Type"] == "" {
+		if err := sniffContentType(req); err != nil {
+			return nil, errors.Wrap(err, "failed to detect content type")
+		}
+	}
+
+	// Generated once per call, so every retry of it carries the same key.
+	if name := c.config.IdempotencyKeyHeader; name != "" && !isIdempotent(method) && req.Header.Get(name) == "" {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate idempotency key")
+		}
+		req.Header.Set(name, key)
+	}
+
+	if (opts.ExpectContinue || c.config.ExpectContinue) && hasBody(req) {
+		req.Header.Set("Expect", "100-continue")
+	}
+
+	return req, nil
+}
+
+// roundTrip runs the interceptors on a built request, sends it, and checks the response.
+func (c *Client) roundTrip(req *http.Request, opts *RequestConfig) (*http.Response, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	ctx := req.Context()
+
+	for _, intercept := range c.config.Interceptors {
+		if err := intercept(ctx, req); err != nil {
+			return nil, errors.Wrap(err, "request interceptor failed")
+		}
+	}
+
+	if limit := c.config.MaxRequestSize; limit > 0 && hasBody(req) {
+		if req.ContentLength > limit {
+			req.Body.Close()
+			return nil, errors.NewHTTPError(nil, ErrRequestTooLarge, "request failed")
+		}
+		limitRequestBody(req, limit)
+	}
+
 	// Perform the request
-	resp, err := c.doer.Do(req)
+	start := c.config.Clock.Now()
+	maxSize := c.responseLimit(opts)
+	resp, err := c.send(req, c.flightKey(req, opts), maxSize)
+	if opts.Trace != nil {
+		opts.Trace.finish(c.config.Clock)
+	}
 	if err != nil {
-		return nil, errors.NewHTTPError(nil, err, "request failed")
+		return nil, errors.NewHTTPError(nil, c.timeoutError(req, start, classifyError(ctx, err)), "request failed")
+	}
+
+	if limit := c.config.MaxResponseHeaders; limit > 0 && countHeaders(resp.Header) > limit {
+		resp.Body.Close()
+		return nil, errors.NewHTTPError(nil, ErrTooManyHeaders, "request failed")
+	}
+
+	resp.Body = limitBody(resp.Body, maxSize)
+
+	for _, transform := range c.config.Transforms {
+		next, err := transform(resp)
+		if err == nil && next == nil {
+			err = errors.New("transform returned no response")
+		}
+		if err != nil {
+			return resp, errors.NewHTTPError(resp, err, "response transform failed")
+		}
+		resp = next
+	}
+
+	if c.config.RedirectsAsErrors && IsRedirect(resp) {
+		if c.config.AutoDrainOnError {
+			DrainAndClose(resp)
+			resp.Body = http.NoBody
+		}
+		return resp, &HTTPError{HTTPError: errors.NewHTTPError(resp, ErrRedirect, "request was redirected")}
 	}
 
 	// Check if the response indicates an error
-	if resp.StatusCode >= 400 {
-		return resp, errors.NewHTTPError(resp, nil, "request returned error status")
+	if !c.config.StatusValidator(resp.StatusCode) {
+		details := c.errorDetails(resp)
+		if c.config.AutoDrainOnError {
+			DrainAndClose(resp)
+			resp.Body = http.NoBody
+		}
+		return resp, &HTTPError{
+			HTTPError: errors.NewHTTPError(resp, nil, "request returned error status"),
+			Details:   details,
+		}
+	}
+
+	for _, intercept := range c.config.BodyInterceptors {
+		if body := intercept(resp.Body); body != nil {
+			resp.Body = body
+		}
+	}
+
+	if len(c.config.BodyValidators) > 0 {
+		if err := c.validateBody(resp); err != nil {
+			return resp, errors.NewHTTPError(resp, err, "response body validation failed")
+		}
 	}
 
 	return resp, err
 }
 
-// resolveURL constructs the full request URL from a path or URL.
-func (c *Client) resolveURL(pathOrURL string, queryParams url.Values) (*url.URL, error) {
+// countHeaders returns the number of header fields in h, counting each value of repeated headers.
+func countHeaders(h http.Header) int {
+	n := 0
+	for _, values := range h {
+		n += len(values)
+	}
+	return n
+}
+
+// maxValidatedBodySize bounds how many response body bytes are buffered for body validators.
+const maxValidatedBodySize = 1 << 20
+
+// validateBody runs the body validators on the start of the response body, which is then put back
+// in front of the rest of the body. Responses without a body are not validated.
+func (c *Client) validateBody(resp *http.Response) error {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxValidatedBodySize))
+	resp.Body = &peekedBody{
+		Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+		Closer: resp.Body,
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	for _, validate := range c.config.BodyValidators {
+		if err := validate(resp, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxErrorBodySize bounds how many bytes of an error response body are read into HTTPError.Details.
+const maxErrorBodySize = 64 << 10
+
+// errorDetails reads the start of an error response body, which is then put back in front of the
+// rest of the body, and decodes it with the configured error decoder. Without a decoder, or if
+// decoding fails, the raw body is returned as a string. Empty bodies have no details.
+func (c *Client) errorDetails(resp *http.Response) any {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body = &peekedBody{
+		Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+		Closer: resp.Body,
+	}
+	if err != nil || len(body) == 0 {
+		return nil
+	}
+
+	if c.config.ErrorDecoder != nil {
+		details, err := c.config.ErrorDecoder(body)
+		if err == nil {
+			return details
+		}
+		c.logger.Debug("Failed to decode error response body", logger.ErrorKey, err.Error())
+	}
+
+	return string(body)
+}
+
+// defaultStatusValidator accepts every status below 400.
+func defaultStatusValidator(status int) bool {
+	return status < 400
+}
+
+// requestBody selects the request body from opts, along with a GetBody function when the
+// body can be rewound but http.NewRequest wouldn't detect it on its own.
+func requestBody(opts *RequestConfig) (io.Reader, func() (io.ReadCloser, error), error) {
+	switch {
+	case opts.Body != nil:
+		switch opts.Body.(type) {
+		case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+			// http.NewRequest sets GetBody for these.
+			return opts.Body, opts.GetBody, nil
+		}
+
+		seeker, ok := opts.Body.(io.ReadSeeker)
+		if !ok || opts.GetBody != nil {
+			return opts.Body, opts.GetBody, nil
+		}
+
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			// Not actually seekable (e.g. a pipe), send it once.
+			return opts.Body, nil, nil
+		}
+
+		// The transport closes the body once sent, which would prevent rewinding it, so it's
+		// shielded with a NopCloser: the caller keeps ownership of closing it.
+		if readerAt, ok := opts.Body.(io.ReaderAt); ok {
+			// Files and the like can be read from anywhere, so each copy gets its own independent
+			// reader, leaving the position of the body being sent alone.
+			getBody := func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(readerAt, start, math.MaxInt64-start)), nil
+			}
+			return io.NopCloser(seeker), getBody, nil
+		}
+
+		// Other seekers can only be rewound: every copy shares the read position of the body, so
+		// GetBody must not be called while the body returned before is still in use.
+		getBody := func() (io.ReadCloser, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(seeker), nil
+		}
+		return io.NopCloser(seeker), getBody, nil
+
+	case opts.BodyBytes != nil:
+		return bytes.NewReader(opts.BodyBytes), opts.GetBody, nil
+
+	case opts.GetBody != nil:
+		body, err := opts.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, opts.GetBody, nil
+	}
+
+	return nil, nil, nil
+}
+
+// remainingLength returns the number of bytes left to read from r if it is an io.Seeker, seeking back
+// to where it was. It reports false for other readers, when seeking fails or when nothing is left,
+// which some special files report despite having content.
+func remainingLength(r io.Reader) (int64, bool) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+
+	if end <= current {
+		return 0, false
+	}
+	return end - current, true
+}
+
+// bufferBody reads a body of unknown length into memory, up to max bytes, so that it is sent with a
+// Content-Length. If the body is larger, what was read is sent first followed by the rest, chunked.
+func bufferBody(req *http.Request, max int64) error {
+	buf, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	if err != nil {
+		return err
+	}
+
+	if int64(len(buf)) > max {
+		req.Body = &peekedBody{
+			Reader: io.MultiReader(bytes.NewReader(buf), req.Body),
+			Closer: req.Body,
+		}
+		return nil
+	}
+
+	if err := req.Body.Close(); err != nil {
+		return err
+	}
+
+	req.ContentLength = int64(len(buf))
+	req.Body = io.NopCloser(bytes.NewReader(buf))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	if len(buf) == 0 {
+		req.Body = http.NoBody
+	}
+
+	return nil
+}
+
+// withRequestHeader returns a copy of opts with the header set, leaving the caller's config untouched.
+func withRequestHeader(opts *RequestConfig, key, value string) *RequestConfig {
+	cp := RequestConfig{}
+	if opts != nil {
+		cp = *opts
+	}
+
+	cp.Headers = make(map[string]string, len(cp.Headers)+1)
+	if opts != nil {
+		maps.Copy(cp.Headers, opts.Headers)
+	}
+	cp.Headers[key] = value
+
+	return &cp
+}
+
+// hasHeader reports whether headers sets the header key, whatever its case.
+func hasHeader(headers map[string]string, key string) bool {
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffLen is the number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// sniffContentType sets the Content-Type header of req from the first bytes of its body.
+// Bodies that http.DetectContentType reports as plain text but start like a JSON object
+// or array are labeled application/json. The body remains fully readable afterwards.
+func sniffContentType(req *http.Request) error {
+	if !hasBody(req) {
+		return nil
+	}
+
+	var head []byte
+	if req.GetBody != nil {
+		// Read from a copy, leaving the actual body untouched.
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		head, err = io.ReadAll(io.LimitReader(body, sniffLen))
+		body.Close()
+		if err != nil {
+			return err
+		}
+		// A seekable body may share its position with the copy, start it over.
+		if req.Body, err = req.GetBody(); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		head, err = io.ReadAll(io.LimitReader(req.Body, sniffLen))
+		if err != nil {
+			return err
+		}
+		req.Body = &peekedBody{
+			Reader: io.MultiReader(bytes.NewReader(head), req.Body),
+			Closer: req.Body,
+		}
+	}
+
+	if len(head) == 0 {
+		return nil
+	}
+
+	contentType := http.DetectContentType(head)
+	if strings.HasPrefix(contentType, "text/plain") {
+		if trimmed := bytes.TrimLeft(head, " \t\r\n"); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			contentType = "application/json"
+		}
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	return nil
+}
+
+// hasBody reports whether req carries a request body.
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+// resolveURL constructs the full request URL from a path or URL, resolving paths against the base
+// URL selected for ctx.
+func (c *Client) resolveURL(ctx context.Context, pathOrURL string, queryParams url.Values) (*url.URL, error) {
 	u, err := url.Parse(pathOrURL)
 	if err != nil {
 		return nil, errors.Wrapf(err, "invalid URL or path: %s", pathOrURL)
 	}
 
 	if u.IsAbs() {
-		return c.addQueryParams(u, queryParams), nil
+		if c.userInfo != nil && u.User == nil && (c.baseURL == nil || strings.EqualFold(u.Host, c.baseURL.Host)) {
+			u.User = c.userInfo
+		}
+		return c.addQueryParams(u, c.withDefaultParams(u, queryParams)), nil
 	}
 
-	if c.baseURL == nil {
+	base, err := c.base(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
 		return nil, errors.New("cannot resolve relative path without a base URL")
 	}
 
-	resolved := c.baseURL.JoinPath(u.Path)
+	var resolved *url.URL
+	if c.resolveRef {
+		resolved = base.ResolveReference(u)
+	} else {
+		resolved = base.JoinPath(u.Path)
+	}
+	if c.userInfo != nil {
+		resolved.User = c.userInfo
+	}
+
+	return c.addQueryParams(resolved, c.withDefaultParams(resolved, queryParams)), nil
+}
+
+// base returns the base URL relative paths are resolved against for ctx, see WithBaseURLFunc.
+func (c *Client) base(ctx context.Context) (*url.URL, error) {
+	if c.baseURLFunc == nil {
+		return c.baseURL, nil
+	}
+
+	u, err := c.baseURLFunc(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select base URL")
+	}
+	if u == nil {
+		return c.baseURL, nil
+	}
+	if !u.IsAbs() {
+		return nil, errors.New("selected base URL must be absolute (have scheme and host)")
+	}
+
+	return u, nil
+}
+
+// appendPathSegments appends segments to the path of u, escaping each one as a whole.
+func appendPathSegments(u *url.URL, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+
+	escaped := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, seg := range segments {
+		escaped += "/" + url.PathEscape(seg)
+	}
+
+	// Path holds the decoded form, RawPath keeps the escaping of "/" within segments.
+	u.Path, _ = url.PathUnescape(escaped)
+	u.RawPath = escaped
+}
+
+// withDefaultParams merges the client's default query parameters into params.
+// Keys present in params or already in the URL's query string take precedence over the defaults.
+func (c *Client) withDefaultParams(u *url.URL, params url.Values) url.Values {
+	if len(c.defaultParams) == 0 {
+		return params
+	}
+
+	merged := make(url.Values, len(params)+len(c.defaultParams))
+	maps.Copy(merged, params)
+
+	existing := u.Query()
+	for k, values := range c.defaultParams {
+		if _, ok := merged[k]; ok || existing.Has(k) {
+			continue
+		}
+		merged[k] = values
+	}
 
-	return c.addQueryParams(resolved, queryParams), nil
+	return merged
 }
 
 // addQueryParams appends query parameters to a URL.
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxDrainBytes bounds how much of a response body DrainAndClose will discard.
+// Past this point closing the connection is cheaper than reading the rest.
+const maxDrainBytes = 64 << 10
+
+// DrainAndClose discards what remains of the response body, up to a cap, and closes it.
+//
+// A body must be read to EOF and closed for the underlying connection to be reused
+// by keep-alive; closing an unread body forces a new connection for the next request.
+// Callers that don't need the body, or only read part of it, should defer this instead
+// of resp.Body.Close:
+//
+//	resp, err := c.Get(ctx, "/users", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer client.DrainAndClose(resp)
+//
+// It is safe to call with a nil response or body.
+func DrainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	_ = resp.Body.Close()
+}
+
+// ReadWithTrailers reads the whole response body, closes it, and returns it along with the response
+// trailers.
+//
+// Trailers are sent after the body, so resp.Trailer only holds their values once the body has been
+// read to EOF: before that, it only lists the announced trailer names with empty values. This helper
+// enforces that ordering. The returned trailers are nil if the response has none.
+func ReadWithTrailers(resp *http.Response) ([]byte, http.Header, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, nil, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return body, nil, err
+	}
+
+	return body, resp.Trailer, nil
+}
+
+// IsRedirect reports whether resp is a redirect that was not followed: a 301, 302, 303, 307 or 308
+// response with a Location header. Its target, resolved against the request URL, is given by
+// resp.Location. See WithDisableRedirects and WithRedirectsAsErrors.
+func IsRedirect(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return resp.Header.Get("Location") != ""
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trackingBody records whether it was fully read and closed.
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDrainAndClose(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader("leftover")}
+
+	DrainAndClose(&http.Response{Body: body})
+
+	if !body.closed {
+		t.Errorf("Expected body to be closed")
+	}
+	if n, _ := body.Read(make([]byte, 1)); n != 0 {
+		t.Errorf("Expected body to be drained")
+	}
+
+	// Must not panic.
+	DrainAndClose(nil)
+	DrainAndClose(&http.Response{})
+}
+
+func TestClient_AutoDrainOnError(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader("not found")}
+	doer := doerFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: body}, nil
+	})
+
+	c, err := New(WithCustomDoer(doer), WithAutoDrainOnError())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "https://example.com", nil)
+	if err == nil {
+		t.Fatalf("Expected error but got nil")
+	}
+	if !body.closed {
+		t.Errorf("Expected error body to be closed")
+	}
+	if resp.Body != http.NoBody {
+		t.Errorf("Expected response body to be replaced with http.NoBody")
+	}
+}
+
+// countingBody counts the bytes read through it.
+type countingBody struct {
+	io.ReadCloser
+	n *int
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += n
+	return n, err
+}
+
+func TestClient_ResponseBodyInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCount int
+	}{
+		{name: "success is intercepted", status: http.StatusOK, wantCount: 5},
+		{name: "error is not intercepted", status: http.StatusInternalServerError, wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &trackingBody{Reader: strings.NewReader("hello")}
+			doer := doerFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: tt.status, Body: body}, nil
+			})
+
+			var count int
+			var order []string
+			c, err := New(
+				WithCustomDoer(doer),
+				WithResponseBodyInterceptor(func(rc io.ReadCloser) io.ReadCloser {
+					order = append(order, "count")
+					return countingBody{ReadCloser: rc, n: &count}
+				}),
+				WithResponseBodyInterceptor(func(rc io.ReadCloser) io.ReadCloser {
+					order = append(order, "outer")
+					return rc
+				}),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, _ := c.Get(context.Background(), "https://example.com", nil)
+			io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if count != tt.wantCount {
+				t.Errorf("Interceptor counted %d bytes, want %d", count, tt.wantCount)
+			}
+			if tt.wantCount > 0 && strings.Join(order, ",") != "count,outer" {
+				t.Errorf("Interceptors applied in order %v", order)
+			}
+			if !body.closed {
+				t.Errorf("Expected Close to reach the original body")
+			}
+		})
+	}
+}
+
+func TestReadWithTrailers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		io.WriteString(w, "payload")
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer srv.Close()
+
+	c, _ := New()
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := resp.Trailer.Get("Grpc-Status"); got != "" {
+		t.Fatalf("Trailer populated before EOF: %q", got)
+	}
+
+	body, trailer, err := ReadWithTrailers(resp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(body) != "payload" {
+		t.Errorf("Got body %q, want %q", body, "payload")
+	}
+	if got := trailer.Get("Grpc-Status"); got != "0" {
+		t.Errorf("Got trailer %q, want %q", got, "0")
+	}
+}
+
+func TestClient_Redirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "moved")
+	})
+	mux.HandleFunc("/cached", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		opts         []ClientOption
+		wantStatus   int
+		wantRedirect bool
+		wantErr      error
+	}{
+		{name: "followed by default", path: "/old", wantStatus: http.StatusOK},
+		{
+			name:         "disabled",
+			path:         "/old",
+			opts:         []ClientOption{WithDisableRedirects()},
+			wantStatus:   http.StatusFound,
+			wantRedirect: true,
+		},
+		{
+			name:         "disabled as errors",
+			path:         "/old",
+			opts:         []ClientOption{WithDisableRedirects(), WithRedirectsAsErrors()},
+			wantStatus:   http.StatusFound,
+			wantRedirect: true,
+			wantErr:      ErrRedirect,
+		},
+		{
+			name:       "not modified is not a redirect",
+			path:       "/cached",
+			opts:       []ClientOption{WithDisableRedirects(), WithRedirectsAsErrors()},
+			wantStatus: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(append(tt.opts, WithBaseURL(srv.URL))...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Get(context.Background(), tt.path, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp == nil {
+				t.Fatalf("Expected a response")
+			}
+			defer DrainAndClose(resp)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if IsRedirect(resp) != tt.wantRedirect {
+				t.Errorf("IsRedirect = %v, want %v", IsRedirect(resp), tt.wantRedirect)
+			}
+			if tt.wantRedirect {
+				loc, err := resp.Location()
+				if err != nil || loc.String() != srv.URL+"/new" {
+					t.Errorf("Got location %v (%v), want %s", loc, err, srv.URL+"/new")
+				}
+			}
+		})
+	}
+}
+
+func TestClient_FollowCreated(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var fetches []*http.Request
+	var created time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/items", func(w http.ResponseWriter, r *http.Request) {
+		created = time.Now()
+		w.Header().Set("Location", "items/7")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":7}`)
+	})
+	mux.HandleFunc("POST /api/bare", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":8}`)
+	})
+	mux.HandleFunc("GET /api/items/7", func(w http.ResponseWriter, r *http.Request) {
+		fetches = append(fetches, r)
+		io.WriteString(w, `{"id":7,"name":"widget"}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		path        string
+		want        item
+		wantFetches int
+	}{
+		{name: "disabled by default", path: "/api/items", want: item{ID: 7}},
+		{name: "followed", opts: []ClientOption{WithFollowCreated()}, path: "/api/items", want: item{ID: 7, Name: "widget"}, wantFetches: 1},
+		{name: "without location", opts: []ClientOption{WithFollowCreated()}, path: "/api/bare", want: item{ID: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches = nil
+			c, err := New(append(tt.opts, WithBaseURL(srv.URL))...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var got item
+			opts := &RequestConfig{Headers: map[string]string{"X-Tenant": "acme"}}
+			if err := c.PostJSON(context.Background(), tt.path, item{Name: "widget"}, opts, &got); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Got %+v, want %+v", got, tt.want)
+			}
+			if len(fetches) != tt.wantFetches {
+				t.Fatalf("Got %d follow-up requests, want %d", len(fetches), tt.wantFetches)
+			}
+			for _, r := range fetches {
+				if r.Header.Get("X-Tenant") != "acme" || r.Header.Get("Content-Type") != "" {
+					t.Errorf("Got follow-up headers %v, want the original ones but Content-Type", r.Header)
+				}
+			}
+		})
+	}
+
+	t.Run("request settings carried", func(t *testing.T) {
+		c, err := New(WithBaseURL(srv.URL), WithFollowCreated())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The 201 body fits the limit, the created resource doesn't.
+		var got item
+		err = c.PostJSON(context.Background(), "/api/items", item{Name: "widget"}, &RequestConfig{MaxResponseSize: 10}, &got)
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+		}
+
+		trace := &RequestTrace{}
+		if err := c.PostJSON(context.Background(), "/api/items", item{Name: "widget"}, &RequestConfig{Trace: trace}, &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !trace.Start.After(created) || trace.Total <= 0 {
+			t.Errorf("Got trace %+v, want the follow-up GET timed", trace)
+		}
+	})
+}
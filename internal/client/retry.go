@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/logger"
+)
+
+const (
+	// defaultRetryBaseDelay is the backoff before the first retry, doubled on each following one.
+	defaultRetryBaseDelay = 200 * time.Millisecond
+
+	// defaultRetryMaxDelay caps the backoff between two attempts.
+	defaultRetryMaxDelay = 5 * time.Second
+
+	// minRetryBudget is the least amount of time left before the context deadline
+	// for a retry to be worth attempting after its backoff.
+	minRetryBudget = 100 * time.Millisecond
+
+	// defaultIdempotencyKeyHeader is the header carrying idempotency keys, see WithIdempotencyKey.
+	defaultIdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// retryAttemptsKey is the context key under which a per-request retry count is stored, overriding
+// retryTransport.Retries. See RequestConfig.RetryAttempts.
+type retryAttemptsKey struct{}
+
+// retryTransport retries requests that failed with a transient error or status.
+// Only idempotent methods, or requests carrying an idempotency key, are retried, and only when the
+// request body can be rewound.
+type retryTransport struct {
+	Next      http.RoundTripper
+	Retries   int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	MinBudget time.Duration
+	Clock     Clock
+
+	// RetryOn, when set, replaces shouldRetry to decide whether an attempt is retried.
+	RetryOn func(resp *http.Response, err error) bool
+
+	// Methods, when set, replaces the idempotent methods as the ones whose requests are retryable.
+	Methods []string
+
+	// IdempotencyHeader, when set, makes requests of any method carrying this header retryable.
+	IdempotencyHeader string
+
+	// Logger, when set, receives a warning for every retry.
+	Logger logger.Logger
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+// It retries the request with exponential backoff, honoring Retry-After, until it succeeds,
+// the retries are exhausted, or the context deadline is too close for another attempt.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	retries := t.Retries
+	if n, ok := ctx.Value(retryAttemptsKey{}).(int); ok {
+		retries = n
+	}
+
+	if retries <= 0 || !t.isRetryable(req) || (hasBody(req) && req.GetBody == nil) {
+		return t.next().RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next().RoundTrip(req)
+
+		if attempt >= retries || !t.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if !t.hasTimeFor(ctx, delay+t.MinBudget) {
+			// Another attempt can't complete before the deadline, return what we have.
+			return resp, err
+		}
+
+		t.logRetry(req, attempt+1, resp, err, delay)
+		DrainAndClose(resp)
+
+		if err := t.clock().Sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// next returns the next RoundTripper, or http.DefaultTransport if nil.
+func (t *retryTransport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// clock returns the Clock used for backoff, or the real clock if nil.
+func (t *retryTransport) clock() Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return realClock{}
+}
+
+// logRetry warns that req is retried after attempt (1-based) failed with resp or err.
+func (t *retryTransport) logRetry(req *http.Request, attempt int, resp *http.Response, err error, delay time.Duration) {
+	if t.Logger == nil {
+		return
+	}
+
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	} else {
+		reason = "status " + strconv.Itoa(resp.StatusCode)
+	}
+
+	requestLogger(t.Logger, req).WithFields(map[string]any{
+		"method":  req.Method,
+		"url":     req.URL.String(),
+		"attempt": attempt,
+		"reason":  reason,
+		"backoff": delay.String(),
+	}).Warn("Retrying HTTP Request")
+}
+
+// shouldRetry reports whether the attempt that produced resp and err should be retried, using
+// RetryOn if set. Nothing is retried once the context is done.
+func (t *retryTransport) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if t.RetryOn == nil {
+		return shouldRetry(ctx, resp, err)
+	}
+	return ctx.Err() == nil && t.RetryOn(resp, err)
+}
+
+// backoff returns the delay before the retry following attempt (0-based).
+// A Retry-After header on the response takes precedence over the exponential backoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.clock().Now()); ok {
+			return min(d, t.MaxDelay)
+		}
+	}
+
+	delay := t.BaseDelay << attempt
+	if delay <= 0 || delay > t.MaxDelay {
+		return t.MaxDelay
+	}
+	return delay
+}
+
+// shouldRetry reports whether a failed attempt is worth retrying: transport errors other than
+// the context being done, a rejected host or an oversized body, 429 Too Many Requests, and 5xx
+// statuses except 501 Not Implemented.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, ErrHostNotAllowed) && !errors.Is(err, ErrRequestTooLarge)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusNotImplemented:
+		return false
+	default:
+		return resp.StatusCode >= 500
+	}
+}
+
+// isIdempotent reports whether requests with method can safely be sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryable reports whether req can safely be sent more than once, either because its method is
+// idempotent, or among Methods when set, or because it carries an idempotency key the server can
+// dedupe it with.
+func (t *retryTransport) isRetryable(req *http.Request) bool {
+	if t.IdempotencyHeader != "" && req.Header.Get(t.IdempotencyHeader) != "" {
+		return true
+	}
+	if t.Methods != nil {
+		return slices.Contains(t.Methods, req.Method)
+	}
+	return isIdempotent(req.Method)
+}
+
+// hasTimeFor reports whether ctx leaves at least d before its deadline, if it has one.
+func (t *retryTransport) hasTimeFor(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || deadline.Sub(t.clock().Now()) >= d
+}
+
+// rewindRequest returns a copy of req with a fresh body obtained from GetBody.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if !hasBody(req) {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as an HTTP date
+// (relative to now).
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// newIdempotencyKey returns a random UUID (version 4) to use as an idempotency key.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
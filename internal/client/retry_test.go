@@ -0,0 +1,502 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/logger"
+)
+
+// statusSequence returns a RoundTripper answering with the given statuses in order,
+// repeating the last one, and counting the calls made.
+func statusSequence(calls *atomic.Int32, statuses ...int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := int(calls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		return stringResponse(status, http.StatusText(status)), nil
+	})
+}
+
+func newTestRetryTransport(next http.RoundTripper, retries int) *retryTransport {
+	return &retryTransport{
+		Next:      next,
+		Retries:   retries,
+		BaseDelay: time.Millisecond,
+		MaxDelay:  10 * time.Millisecond,
+		MinBudget: time.Millisecond,
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       io.Reader
+		statuses   []int
+		retries    int
+		wantCalls  int32
+		wantStatus int
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, retries: 3, wantCalls: 1, wantStatus: 200},
+		{name: "recovers", method: http.MethodGet, statuses: []int{503, 502, 200}, retries: 3, wantCalls: 3, wantStatus: 200},
+		{name: "exhausted", method: http.MethodGet, statuses: []int{503}, retries: 2, wantCalls: 3, wantStatus: 503},
+		{name: "disabled", method: http.MethodGet, statuses: []int{503}, retries: 0, wantCalls: 1, wantStatus: 503},
+		{name: "client error", method: http.MethodGet, statuses: []int{404}, retries: 3, wantCalls: 1, wantStatus: 404},
+		{name: "not implemented", method: http.MethodGet, statuses: []int{501}, retries: 3, wantCalls: 1, wantStatus: 501},
+		{name: "too many requests", method: http.MethodGet, statuses: []int{429, 200}, retries: 3, wantCalls: 2, wantStatus: 200},
+		{name: "POST not retried", method: http.MethodPost, statuses: []int{503, 200}, retries: 3, wantCalls: 1, wantStatus: 503},
+		{
+			name:       "PUT with rewindable body",
+			method:     http.MethodPut,
+			body:       strings.NewReader("payload"),
+			statuses:   []int{503, 200},
+			retries:    3,
+			wantCalls:  2,
+			wantStatus: 200,
+		},
+		{
+			name:       "PUT with one-shot body",
+			method:     http.MethodPut,
+			body:       io.MultiReader(strings.NewReader("payload")),
+			statuses:   []int{503, 200},
+			retries:    3,
+			wantCalls:  1,
+			wantStatus: 503,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			tr := newTestRetryTransport(statusSequence(&calls, tt.statuses...), tt.retries)
+
+			req, _ := http.NewRequest(tt.method, "https://example.com", tt.body)
+			resp, err := tr.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("Made %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ReplaysBody(t *testing.T) {
+	var bodies []string
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 3 {
+			return stringResponse(http.StatusServiceUnavailable, ""), nil
+		}
+		return stringResponse(http.StatusOK, ""), nil
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, "https://example.com", strings.NewReader("payload"))
+	if _, err := newTestRetryTransport(next, 3).RoundTrip(req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(bodies, ",") != "payload,payload,payload" {
+		t.Errorf("Got bodies %q, want the payload on every attempt", bodies)
+	}
+}
+
+func TestRetryTransport_TransportError(t *testing.T) {
+	var calls atomic.Int32
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return stringResponse(http.StatusOK, ""), nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if _, err := newTestRetryTransport(next, 3).RoundTrip(req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Made %d calls, want 2", got)
+	}
+}
+
+func TestRetryTransport_StopsNearDeadline(t *testing.T) {
+	var calls atomic.Int32
+	tr := &retryTransport{
+		Next:      statusSequence(&calls, http.StatusServiceUnavailable),
+		Retries:   10,
+		BaseDelay: 20 * time.Millisecond,
+		MaxDelay:  time.Second,
+		MinBudget: 20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	start := time.Now()
+	resp, err := tr.RoundTrip(req)
+
+	if err != nil {
+		t.Fatalf("Expected the last response instead of an error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected to give up before the deadline, took %s", time.Since(start))
+	}
+	// Delays are 20, 40, 80ms: a fourth retry would need 160ms+20ms with only ~60ms left.
+	if got := calls.Load(); got < 2 || got >= 11 {
+		t.Errorf("Made %d calls, want a few but fewer than the 11 allowed", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{header: "", wantOK: false},
+		{header: "3", want: 3 * time.Second, wantOK: true},
+		{header: "-1", wantOK: false},
+		{header: "soon", wantOK: false},
+		{header: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header, time.Now())
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// fakeClock is a Clock whose Sleep advances its time instantly, recording the requested delays.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	return ctx.Err()
+}
+
+func TestClient_WithClock(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "4")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	c, err := New(WithBaseURL(srv.URL), WithRetryAttempts(3), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	resp, err := c.Get(context.Background(), "/", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Retries waited %s in real time, want the fake clock to skip the delays", elapsed)
+	}
+	if want := []time.Duration{4 * time.Second, 4 * time.Second}; !slices.Equal(clock.sleeps, want) {
+		t.Errorf("Slept %v, want %v", clock.sleeps, want)
+	}
+}
+
+func TestRetryTransport_DeadlineUsesClock(t *testing.T) {
+	var calls atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+	tr := newTestRetryTransport(statusSequence(&calls, http.StatusServiceUnavailable), 10)
+	tr.BaseDelay = 20 * time.Second
+	tr.MaxDelay = time.Minute
+	tr.Clock = clock
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.now.Add(time.Minute))
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// Delays are 20s then 40s: after the first one only 40s are left on the fake clock.
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Made %d calls, want 2", got)
+	}
+}
+
+func TestClient_WithRetryOn(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			io.WriteString(w, `{"status":"pending"}`)
+			return
+		}
+		io.WriteString(w, `{"status":"done"}`)
+	}))
+	defer srv.Close()
+
+	pending := func(resp *http.Response, err error) bool {
+		if err != nil {
+			return false
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return strings.Contains(string(body), "pending")
+	}
+
+	c, err := New(WithBaseURL(srv.URL), WithRetryAttempts(5), WithRetryOn(pending), WithClock(&fakeClock{now: time.Now()}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "/job", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"status":"done"}` {
+		t.Errorf("Got %q, want the final body", body)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Made %d calls, want 3", got)
+	}
+}
+
+func TestClient_WithRetryMethods(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		method    string
+		wantCalls int32
+	}{
+		{name: "POST not retried by default", method: http.MethodPost, wantCalls: 1},
+		{name: "GET retried by default", method: http.MethodGet, wantCalls: 3},
+		{name: "POST allowed", opts: []ClientOption{WithRetryMethods("get", "post")}, method: http.MethodPost, wantCalls: 3},
+		{name: "GET not allowed", opts: []ClientOption{WithRetryMethods(http.MethodPost)}, method: http.MethodGet, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+
+			opts := append([]ClientOption{WithBaseURL(srv.URL), WithRetryAttempts(2), WithClock(&fakeClock{now: time.Now()})}, tt.opts...)
+			c, err := New(opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, _ := c.Send(context.Background(), tt.method, "/", nil, &RequestConfig{BodyBytes: []byte("{}")})
+			DrainAndClose(resp)
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("Made %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_WithIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithRetryAttempts(3), WithIdempotencyKey(""), WithClock(&fakeClock{now: time.Now()}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Post(context.Background(), "/orders", &RequestConfig{Body: strings.NewReader(`{"item":1}`)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(keys) != 3 {
+		t.Fatalf("Made %d attempts, want 3", len(keys))
+	}
+	if keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Got keys %q, want the same key on every attempt", keys)
+	}
+
+	resp, err = c.Post(context.Background(), "/orders", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if keys[3] == keys[0] {
+		t.Errorf("Expected a new key for another call, got %q twice", keys[0])
+	}
+}
+
+func TestClient_PerRequestRetryAttempts(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithRetryAttempts(1), WithClock(&fakeClock{now: time.Now()}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		attempts  *int
+		wantCalls int32
+	}{
+		{name: "client default", wantCalls: 2},
+		{name: "override up", attempts: intPtr(3), wantCalls: 4},
+		{name: "override to zero", attempts: intPtr(0), wantCalls: 1},
+		{name: "negative", attempts: intPtr(-1), wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+
+			resp, _ := c.Get(context.Background(), "/", &RequestConfig{RetryAttempts: tt.attempts})
+			DrainAndClose(resp)
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("Made %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_LogsRetries(t *testing.T) {
+	var calls atomic.Int32
+	rec := newRecordingLogger()
+	tr := newTestRetryTransport(statusSequence(&calls, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK), 3)
+	tr.Logger = rec
+	tr.Clock = &fakeClock{now: time.Now()}
+
+	ctx := logger.ContextWithFields(context.Background(), map[string]any{"request_id": "abc"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/items", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Logged %d entries, want 2", len(entries))
+	}
+
+	for i, reason := range []string{"status 503", "status 429"} {
+		e := entries[i]
+		if e.Level != logger.WarnLevel || e.Msg != "Retrying HTTP Request" {
+			t.Errorf("Entry %d: got %v %q", i, e.Level, e.Msg)
+		}
+		if e.Fields["attempt"] != i+1 || e.Fields["reason"] != reason {
+			t.Errorf("Entry %d: got attempt %v and reason %v, want %d and %q", i, e.Fields["attempt"], e.Fields["reason"], i+1, reason)
+		}
+		if e.Fields["url"] != "https://example.com/items" || e.Fields["backoff"] == nil {
+			t.Errorf("Entry %d: missing url or backoff in %v", i, e.Fields)
+		}
+		if e.Fields["request_id"] != "abc" {
+			t.Errorf("Entry %d: expected the request-scoped fields, got %v", i, e.Fields)
+		}
+	}
+}
+
+func TestClient_WithTotalTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// The retry hangs until the total deadline aborts it.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(WithRetryAttempts(3), WithTotalTimeout(500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	_, err = c.Get(context.Background(), srv.URL, nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Got %d attempts, want the deadline to hit the second one", got)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the call to stop at the total deadline, took %s", elapsed)
+	}
+}
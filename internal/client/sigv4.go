@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4DateFormat = "20060102T150405Z"
+)
+
+// sigV4Signer signs requests with AWS Signature Version 4.
+// See https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+type sigV4Signer struct {
+	accessKey string
+	secretKey string
+	region    string
+	service   string
+	now       func() time.Time
+}
+
+// sign is a RequestInterceptor that sets the X-Amz-Date and Authorization headers on req.
+// The body is hashed through req.GetBody, so it must be rewindable.
+func (s *sigV4Signer) sign(_ context.Context, req *http.Request) error {
+	payloadHash, err := hashPayload(req)
+	if err != nil {
+		return err
+	}
+
+	t := s.now().UTC()
+	amzDate := t.Format(sigV4DateFormat)
+	scope := strings.Join([]string{t.Format("20060102"), s.region, s.service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL, s.service != "s3"),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), t.Format("20060102"))
+	for _, part := range []string{s.region, s.service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKey, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// hashPayload returns the hex-encoded SHA-256 of the request body, reading it through GetBody.
+// The body is then replaced by a fresh copy, as a seekable one may share its position with the
+// copy hashed.
+func hashPayload(req *http.Request) (string, error) {
+	if !hasBody(req) {
+		return hexSHA256(nil), nil
+	}
+
+	if req.GetBody == nil {
+		return "", fmt.Errorf("sigv4: request body must be rewindable to be signed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", fmt.Errorf("sigv4: failed to get request body: %w", err)
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(h, body)
+	body.Close()
+	if err != nil {
+		return "", fmt.Errorf("sigv4: failed to hash request body: %w", err)
+	}
+
+	if req.Body, err = req.GetBody(); err != nil {
+		return "", fmt.Errorf("sigv4: failed to get request body: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalURI returns the URI-encoded path. Every service except S3 expects each segment
+// to be encoded twice.
+func canonicalURI(u *url.URL, doubleEncode bool) string {
+	if u.Path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(u.Path, "/")
+	for i, seg := range segments {
+		seg = awsURIEncode(seg)
+		if doubleEncode {
+			seg = awsURIEncode(seg)
+		}
+		segments[i] = seg
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query string with encoded keys and values, sorted by key then value.
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+
+	pairs := make([]string, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, awsURIEncode(k)+"="+awsURIEncode(v))
+		}
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders returns the signed header names and the canonical header block.
+// Host, Content-Type and every X-Amz-* header are signed.
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for k, values := range req.Header {
+		name := strings.ToLower(k)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	return strings.Join(names, ";"), b.String()
+}
+
+// awsURIEncode percent-encodes every byte except the unreserved characters A-Z, a-z, 0-9, '-', '.', '_' and '~'.
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
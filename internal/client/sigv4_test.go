@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigV4Signer_Sign(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation.
+	signer := &sigV4Signer{
+		accessKey: "AKIDEXAMPLE",
+		secretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:    "us-east-1",
+		service:   "iam",
+		now:       func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	if err := signer.sign(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q, want %q", got, "20150830T123600Z")
+	}
+}
+
+func TestSigV4Signer_Body(t *testing.T) {
+	signer := &sigV4Signer{
+		accessKey: "AKIDEXAMPLE",
+		secretKey: "secret",
+		region:    "us-east-1",
+		service:   "s3",
+		now:       time.Now,
+	}
+
+	t.Run("rewindable body is hashed and preserved", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/key", strings.NewReader("hello"))
+
+		if err := signer.sign(context.Background(), req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+		if got := req.Header.Get("X-Amz-Content-Sha256"); got != helloSHA256 {
+			t.Errorf("X-Amz-Content-Sha256 = %q, want %q", got, helloSHA256)
+		}
+		if body, _ := io.ReadAll(req.Body); string(body) != "hello" {
+			t.Errorf("Body was consumed by signing, got %q", body)
+		}
+	})
+
+	t.Run("seekable bodies are sent whole", func(t *testing.T) {
+		payload := strings.Repeat("hello ", 200)
+		path := filepath.Join(t.TempDir(), "object")
+		if err := os.WriteFile(path, []byte(payload), 0o600); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var gotHash string
+		var gotBody []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotHash = r.Header.Get("X-Amz-Content-Sha256")
+			gotBody, _ = io.ReadAll(r.Body)
+		}))
+		defer srv.Close()
+
+		c, err := New(WithAWSV4Signer("AKIDEXAMPLE", "secret", "us-east-1", "s3"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		sum := sha256.Sum256([]byte(payload))
+		wantHash := hex.EncodeToString(sum[:])
+
+		bodies := map[string]func() io.Reader{
+			"file": func() io.Reader {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				t.Cleanup(func() { f.Close() })
+				return f
+			},
+			"rewound seeker": func() io.Reader { return opaqueSeeker{strings.NewReader(payload)} },
+		}
+		for name, body := range bodies {
+			t.Run(name, func(t *testing.T) {
+				gotHash, gotBody = "", nil
+				if _, err := c.Post(context.Background(), srv.URL, &RequestConfig{Body: body()}); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if gotHash != wantHash {
+					t.Errorf("X-Amz-Content-Sha256 = %q, want %q", gotHash, wantHash)
+				}
+				if string(gotBody) != payload {
+					t.Errorf("Body has %d bytes, want %d", len(gotBody), len(payload))
+				}
+			})
+		}
+	})
+
+	t.Run("one-shot body is rejected", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/key", io.MultiReader(strings.NewReader("hello")))
+
+		if err := signer.sign(context.Background(), req); err == nil {
+			t.Errorf("Expected error for a non-rewindable body")
+		}
+	})
+}
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		path         string
+		doubleEncode bool
+		want         string
+	}{
+		{path: "", want: "/"},
+		{path: "/", want: "/"},
+		{path: "/documents and settings/", want: "/documents%2520and%2520settings/", doubleEncode: true},
+		{path: "/documents and settings/", want: "/documents%20and%20settings/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			u := &url.URL{Path: tt.path}
+			if got := canonicalURI(u, tt.doubleEncode); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sharedResponse is a response whose body was buffered so it can be handed to several callers.
+type sharedResponse struct {
+	resp *http.Response
+	body []byte
+}
+
+// flightKey returns the key under which req can be coalesced with identical in-flight requests,
+// or "" if it must be sent on its own.
+//
+// Query parameters set with WithCacheKeyIgnore are left out of the key, while a per-request
+// RequestConfig.MaxResponseSize is part of it, the shared body being buffered up to that limit.
+//
+// Requests with per-request headers, or going through request interceptors (which may set
+// credentials from the context, like the SigV4 signer), are never coalesced: the response to
+// one caller must not be handed to another.
+func (c *Client) flightKey(req *http.Request, opts *RequestConfig) string {
+	if c.flight == nil || len(opts.Headers) > 0 || len(c.config.Interceptors) > 0 {
+		return ""
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return ""
+	}
+
+	u := req.URL
+	if len(c.config.CacheKeyIgnore) > 0 && u.RawQuery != "" {
+		stripped := *u
+		stripped.RawQuery = withoutParams(u.RawQuery, c.config.CacheKeyIgnore)
+		u = &stripped
+	}
+
+	key := req.Method + " " + u.String()
+	if limit := c.responseLimit(opts); limit != c.config.MaxResponseSize {
+		key += " max=" + strconv.FormatInt(limit, 10)
+	}
+
+	return key
+}
+
+// withoutParams returns rawQuery without the parameters named in ignore, keeping the others as is,
+// in their order.
+func withoutParams(rawQuery string, ignore []string) string {
+	var kept []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !slices.Contains(ignore, name) {
+			kept = append(kept, param)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// send performs req, sharing the call with identical in-flight requests when key is not empty. The
+// shared body is buffered up to limit bytes, see WithMaxResponseSize.
+func (c *Client) send(req *http.Request, key string, limit int64) (*http.Response, error) {
+	if key == "" {
+		return c.doer.Do(req)
+	}
+
+	ch := c.flight.DoChan(key, func() (any, error) {
+		resp, err := c.doer.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(limitBody(resp.Body, limit))
+		if err != nil {
+			return nil, err
+		}
+
+		return &sharedResponse{resp: resp, body: body}, nil
+	})
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		shared := res.Val.(*sharedResponse)
+		resp := *shared.resp
+		resp.Header = shared.resp.Header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(shared.body))
+
+		return &resp, nil
+	}
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_SingleFlight(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		io.WriteString(w, "shared body")
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithSingleFlight())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	const callers = 10
+	bodies := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get(context.Background(), "/resource", nil)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(b)
+		}()
+	}
+
+	// Let every caller join the in-flight request before the server answers.
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Server received %d requests, want 1", got)
+	}
+	for i, b := range bodies {
+		if b != "shared body" {
+			t.Errorf("Caller %d got body %q", i, b)
+		}
+	}
+}
+
+func TestClient_SingleFlight_MaxResponseSize(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		io.WriteString(w, "shared body")
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithSingleFlight(), WithMaxResponseSize(5))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	limits := []int64{0, 100}
+	bodies := make([]string, len(limits))
+	errs := make([]error, len(limits))
+	var wg sync.WaitGroup
+	for i, limit := range limits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get(context.Background(), "/resource", &RequestConfig{MaxResponseSize: limit})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			bodies[i], errs[i] = string(b), err
+		}()
+	}
+
+	// Requests with different limits must not be coalesced: both must reach the server.
+	for deadline := time.Now().Add(time.Second); hits.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if !errors.Is(errs[0], ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge with the client limit, got %v", errs[0])
+	}
+	if errs[1] != nil || bodies[1] != "shared body" {
+		t.Errorf("Got body %q and error %v with the per-request limit, want the whole body", bodies[1], errs[1])
+	}
+}
+
+func TestClient_SingleFlight_Interceptors(t *testing.T) {
+	type userKey struct{}
+
+	var hits atomic.Int32
+	both := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 2 {
+			close(both)
+		}
+		// Hold the first request until the second arrives, so they overlap.
+		select {
+		case <-both:
+		case <-time.After(time.Second):
+		}
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	auth := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", ctx.Value(userKey{}).(string))
+		return nil
+	}
+
+	c, err := New(WithBaseURL(srv.URL), WithSingleFlight(), WithRequestInterceptor(auth))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	users := []string{"alice", "bob"}
+	bodies := make([]string, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), userKey{}, user)
+			resp, err := c.Get(ctx, "/me", nil)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(b)
+		}()
+	}
+	wg.Wait()
+
+	for i, user := range users {
+		if bodies[i] != user {
+			t.Errorf("%s got the response for %q", user, bodies[i])
+		}
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Server received %d requests, want 2", got)
+	}
+}
+
+func TestClient_FlightKey(t *testing.T) {
+	c, _ := New(WithSingleFlight())
+
+	tests := []struct {
+		name   string
+		method string
+		opts   *RequestConfig
+		want   string
+	}{
+		{name: "GET", method: http.MethodGet, opts: &RequestConfig{}, want: "GET https://example.com/a?b=1"},
+		{name: "HEAD", method: http.MethodHead, opts: &RequestConfig{}, want: "HEAD https://example.com/a?b=1"},
+		{name: "POST", method: http.MethodPost, opts: &RequestConfig{}, want: ""},
+		{name: "per-request headers", method: http.MethodGet, opts: &RequestConfig{Headers: map[string]string{"Authorization": "x"}}, want: ""},
+		{name: "per-request size limit", method: http.MethodGet, opts: &RequestConfig{MaxResponseSize: 10}, want: "GET https://example.com/a?b=1 max=10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "https://example.com/a?b=1", nil)
+			if got := c.flightKey(req, tt.opts); got != tt.want {
+				t.Errorf("Got key %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_FlightKey_IgnoredParams(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		url  string
+		want string
+	}{
+		{name: "kept without ignore", url: "https://example.com/a?b=1&_t=123", want: "GET https://example.com/a?b=1&_t=123"},
+		{
+			name: "ignored param dropped",
+			opts: []ClientOption{WithCacheKeyIgnore("_t")},
+			url:  "https://example.com/a?z=1&_t=123&b=2",
+			want: "GET https://example.com/a?z=1&b=2",
+		},
+		{
+			name: "only ignored params",
+			opts: []ClientOption{WithCacheKeyIgnore("_t", "cb")},
+			url:  "https://example.com/a?_t=123&cb",
+			want: "GET https://example.com/a",
+		},
+		{
+			name: "escaped name",
+			opts: []ClientOption{WithCacheKeyIgnore("a b")},
+			url:  "https://example.com/a?a%20b=1&c=2",
+			want: "GET https://example.com/a?c=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(append(tt.opts, WithSingleFlight())...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			if got := c.flightKey(req, &RequestConfig{}); got != tt.want {
+				t.Errorf("Got key %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/logger"
+)
+
+// RequestTrace holds the timings of a request, filled in when it is set as RequestConfig.Trace.
+// It is populated by the time the request method returns, from net/http/httptrace events.
+// When a request is retried or redirected, the connection phases are those of the last connection
+// established, while Total covers every attempt.
+type RequestTrace struct {
+	// Start is when the request was started.
+	Start time.Time
+
+	// DNSLookup, Connect and TLSHandshake are the durations of the connection phases.
+	// They are zero when an idle connection was reused.
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time from Start to the first byte of the response.
+	TimeToFirstByte time.Duration
+
+	// Total is the time from Start until the response headers were received or the request failed.
+	// It doesn't include reading the body.
+	Total time.Duration
+
+	// ConnReused reports whether the request was sent on a previously used connection.
+	ConnReused bool
+
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+}
+
+// clientTrace returns the httptrace hooks recording into t, starting the trace now.
+func (t *RequestTrace) clientTrace(clock Clock) *httptrace.ClientTrace {
+	t.Start = clock.Now()
+
+	// record runs fn under the lock, with the current time.
+	record := func(fn func(now time.Time)) {
+		now := clock.Now()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		fn(now)
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func(now time.Time) { t.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func(now time.Time) { t.DNSLookup = now.Sub(t.dnsStart) })
+		},
+		ConnectStart: func(_, _ string) {
+			record(func(now time.Time) { t.connectStart = now })
+		},
+		ConnectDone: func(_, _ string, err error) {
+			record(func(now time.Time) {
+				if err == nil {
+					t.Connect = now.Sub(t.connectStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			record(func(now time.Time) { t.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func(now time.Time) { t.TLSHandshake = now.Sub(t.tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func(time.Time) { t.ConnReused = info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			record(func(now time.Time) { t.TimeToFirstByte = now.Sub(t.Start) })
+		},
+	}
+}
+
+// finish records the total duration of the request.
+func (t *RequestTrace) finish(clock Clock) {
+	now := clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Total = now.Sub(t.Start)
+}
+
+// connTrace returns the httptrace hooks logging, at debug level, how the connection of a request to
+// host was obtained. See WithConnTrace.
+func connTrace(log logger.Logger, host string) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			fields := map[string]any{
+				"host":     host,
+				"reused":   info.Reused,
+				"was_idle": info.WasIdle,
+			}
+			if info.WasIdle {
+				fields["idle_time"] = info.IdleTime.String()
+			}
+			if info.Conn != nil {
+				fields["remote_addr"] = info.Conn.RemoteAddr().String()
+			}
+
+			log.WithFields(fields).Debug("HTTP Connection")
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_RequestTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c, err := New(WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := &RequestTrace{}
+	resp, err := c.Get(context.Background(), srv.URL, &RequestConfig{Trace: first})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	DrainAndClose(resp)
+
+	if first.Start.IsZero() || first.Total <= 0 {
+		t.Errorf("Expected start and total to be set, got %+v", first)
+	}
+	if first.ConnReused || first.Connect <= 0 || first.TLSHandshake <= 0 {
+		t.Errorf("Expected a new connection with connect and TLS timings, got %+v", first)
+	}
+	if first.TimeToFirstByte <= 0 || first.TimeToFirstByte > first.Total {
+		t.Errorf("TimeToFirstByte = %s, want within (0, %s]", first.TimeToFirstByte, first.Total)
+	}
+
+	second := &RequestTrace{}
+	resp, err = c.Get(context.Background(), srv.URL, &RequestConfig{Trace: second})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	DrainAndClose(resp)
+
+	if !second.ConnReused || second.TLSHandshake != 0 {
+		t.Errorf("Expected the connection to be reused without a handshake, got %+v", second)
+	}
+}
+
+func TestClient_WithConnTrace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	rec := newRecordingLogger()
+	c, err := New(WithBaseURL(srv.URL), WithLogger(rec), WithConnTrace())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 2 {
+		resp, err := c.Get(context.Background(), "/", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		DrainAndClose(resp)
+	}
+
+	var conns []logEntry
+	for _, e := range rec.Entries() {
+		if e.Msg == "HTTP Connection" {
+			conns = append(conns, e)
+		}
+	}
+
+	if len(conns) != 2 {
+		t.Fatalf("Logged %d connections, want 2", len(conns))
+	}
+	if conns[0].Fields["reused"] != false || conns[1].Fields["reused"] != true {
+		t.Errorf("Got reused %v then %v, want false then true", conns[0].Fields["reused"], conns[1].Fields["reused"])
+	}
+	if conns[1].Fields["was_idle"] != true {
+		t.Errorf("Expected the second connection to come from the idle pool")
+	}
+	if got := conns[0].Fields["remote_addr"]; got != srv.Listener.Addr().String() {
+		t.Errorf("Got remote address %v, want %s", got, srv.Listener.Addr())
+	}
+}
@@ -2,9 +2,16 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"io"
+	"mime"
+	"net"
 	"net/http"
 	"net/http/httputil"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/glwbr/brisa/pkg/logger"
@@ -14,19 +21,35 @@ import (
 type headersTransport struct {
 	Next    http.RoundTripper
 	Headers map[string]string
+
+	// UserAgent, when set, returns a suffix appended to the default User-Agent of each request.
+	UserAgent func(ctx context.Context) string
 }
 
 // RoundTrip implements the http.RoundTripper interface.
 // It adds the configured headers to the request before delegating to the next transport.
 func (t *headersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Apply default headers
-	for k, v := range t.Headers {
+	applyDefaultHeaders(req, t.Headers, t.UserAgent)
+	return t.next().RoundTrip(req)
+}
+
+// applyDefaultHeaders sets the headers missing from req, and appends the userAgent suffix, if any, to
+// the default User-Agent unless req set its own.
+func applyDefaultHeaders(req *http.Request, headers map[string]string, userAgent func(ctx context.Context) string) {
+	explicitUA := req.Header.Get("User-Agent") != ""
+
+	for k, v := range headers {
 		if req.Header.Get(k) == "" {
 			req.Header.Set(k, v)
 		}
 	}
 
-	return t.next().RoundTrip(req)
+	if userAgent != nil && !explicitUA {
+		if suffix := userAgent(req.Context()); suffix != "" {
+			ua := strings.TrimSpace(req.Header.Get("User-Agent") + " " + suffix)
+			req.Header.Set("User-Agent", ua)
+		}
+	}
 }
 
 // next returns the next RoundTripper, or http.DefaultTransport if nil.
@@ -37,35 +60,78 @@ func (t *headersTransport) next() http.RoundTripper {
 	return http.DefaultTransport
 }
 
+// maxLogBodySize bounds how many response body bytes are buffered for debug logging.
+const maxLogBodySize = 16 << 10
+
+// peekedBody is a response body whose first bytes were already consumed and are replayed
+// before the remaining stream, while Close still reaches the original body.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
 // loggingTransport logs HTTP request and response details.
-// Logging is conditional based on the Debug flag.
+// Requests and responses are logged when the Debug flag is set, while failures (4xx and 5xx
+// statuses, transport errors) are logged whatever the flag, as are slow requests whenever
+// SlowThreshold is set.
 type loggingTransport struct {
-	Next   http.RoundTripper
-	Logger logger.Logger
-	Debug  bool
+	Next          http.RoundTripper
+	Logger        logger.Logger
+	Debug         bool
+	SlowThreshold time.Duration
+	Clock         Clock
+
+	// Dump adds the raw request and response header dumps to the debug logs.
+	Dump bool
+	// PrettyJSON indents logged JSON bodies.
+	PrettyJSON bool
 }
 
 // RoundTrip implements the http.RoundTripper interface.
-// It logs the request and response if debugging is enabled.
+// It logs the request and response if debugging is enabled, failures at Warn (4xx) or Error (5xx,
+// transport errors) level, and warns about round trips slower than SlowThreshold.
 func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if !t.Debug {
-		return t.next().RoundTrip(req)
-	}
-
-	start := time.Now()
-
 	var reqBody []byte
-	if req.Body != nil {
-		reqBody, _ = io.ReadAll(req.Body)
+	if t.Debug && req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			// Sending what was read would silently truncate the body, e.g. one past WithMaxRequestSize.
+			t.logFailedRequest(req, 0, err)
+			return nil, err
+		}
 		req.Body = io.NopCloser(bytes.NewBuffer(reqBody))
 	}
 
+	start := t.clock().Now()
 	resp, err := t.next().RoundTrip(req)
+	duration := t.clock().Now().Sub(start)
+
+	if t.SlowThreshold > 0 && duration > t.SlowThreshold {
+		t.logSlowRequest(req, duration)
+	}
+
+	if err != nil && req.Context().Err() != nil {
+		// The caller gave up on the request, which is no failure, and a full dump would only be noise.
+		if t.Debug {
+			t.logCanceledRequest(req, duration, err)
+		}
+		return resp, err
+	}
 
-	t.logRequest(req, reqBody, start)
+	if t.Debug {
+		t.logRequest(req, reqBody, duration)
+	}
 
-	if err == nil && resp != nil {
-		t.logResponse(resp)
+	switch {
+	case err != nil:
+		t.logFailedRequest(req, duration, err)
+	case resp == nil:
+	case t.Debug:
+		t.logResponse(req, resp)
+	case resp.StatusCode >= 400:
+		t.logErrorStatus(req, resp, duration)
 	}
 
 	return resp, err
@@ -79,66 +145,340 @@ func (t *loggingTransport) next() http.RoundTripper {
 	return http.DefaultTransport
 }
 
-// logRequest logs the HTTP request details using the configured logger.
-func (t *loggingTransport) logRequest(req *http.Request, body []byte, start time.Time) {
-	dump, _ := httputil.DumpRequestOut(req, false)
+// clock returns the Clock used to time requests, or the real clock if nil.
+func (t *loggingTransport) clock() Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return realClock{}
+}
+
+// requestLogger returns the logger for req, see requestLogger.
+func (t *loggingTransport) requestLogger(req *http.Request) logger.Logger {
+	return requestLogger(t.Logger, req)
+}
 
+// requestLogger returns the logger for req, see contextLogger.
+func requestLogger(l logger.Logger, req *http.Request) logger.Logger {
+	return contextLogger(l, req.Context())
+}
+
+// contextLogger returns the logger stored in ctx with logger.ContextWithLogger, or l if there is
+// none, carrying ctx and the fields attached to it with logger.ContextWithFields, such as a request
+// ID, or pulled from it by the extractors registered with logger.RegisterContextExtractor.
+func contextLogger(l logger.Logger, ctx context.Context) logger.Logger {
+	if cl, ok := logger.LoggerFromContext(ctx); ok {
+		l = cl
+	}
+
+	l = l.WithContext(ctx)
+	if fields := logger.ContextFields(ctx); len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+
+	return l
+}
+
+// logSlowRequest warns that req took longer than the slow request threshold.
+func (t *loggingTransport) logSlowRequest(req *http.Request, duration time.Duration) {
+	t.requestLogger(req).WithFields(map[string]any{
+		"method":    req.Method,
+		"url":       req.URL.String(),
+		"duration":  duration.String(),
+		"threshold": t.SlowThreshold.String(),
+	}).Warn("Slow HTTP request")
+}
+
+// logCanceledRequest logs a request that failed because its context was canceled or timed out.
+func (t *loggingTransport) logCanceledRequest(req *http.Request, duration time.Duration, err error) {
+	t.requestLogger(req).WithFields(map[string]any{
+		"method":   req.Method,
+		"url":      req.URL.String(),
+		"duration": duration.String(),
+		"error":    err.Error(),
+	}).Debug("HTTP Request canceled")
+}
+
+// logFailedRequest logs, at error level, a request that failed without a response.
+func (t *loggingTransport) logFailedRequest(req *http.Request, duration time.Duration, err error) {
+	t.requestLogger(req).WithFields(map[string]any{
+		"method":   req.Method,
+		"url":      req.URL.String(),
+		"duration": duration.String(),
+		"error":    err.Error(),
+	}).Error("HTTP Request failed")
+}
+
+// logErrorStatus logs a response with an error status, without the details logResponse adds.
+func (t *loggingTransport) logErrorStatus(req *http.Request, resp *http.Response, duration time.Duration) {
+	l := t.requestLogger(req).WithFields(map[string]any{
+		"method":   req.Method,
+		"url":      req.URL.String(),
+		"status":   resp.Status,
+		"duration": duration.String(),
+	})
+	logAt(l, statusLevel(resp.StatusCode), "HTTP Response")
+}
+
+// statusLevel returns the level responses with the given status are logged at: Debug for successes
+// and redirects, Warn for client errors and Error for server errors.
+func statusLevel(status int) logger.Level {
+	switch {
+	case status >= 500:
+		return logger.ErrorLevel
+	case status >= 400:
+		return logger.WarnLevel
+	default:
+		return logger.DebugLevel
+	}
+}
+
+// logAt logs msg to l at level.
+func logAt(l logger.Logger, level logger.Level, msg string) {
+	switch level {
+	case logger.ErrorLevel:
+		l.Error(msg)
+	case logger.WarnLevel:
+		l.Warn(msg)
+	case logger.InfoLevel:
+		l.Info(msg)
+	default:
+		l.Debug(msg)
+	}
+}
+
+// logRequest logs the HTTP request details using the configured logger.
+func (t *loggingTransport) logRequest(req *http.Request, body []byte, duration time.Duration) {
 	fields := map[string]any{
 		"method":   req.Method,
 		"url":      req.URL.String(),
-		"headers":  string(dump),
-		"duration": time.Since(start).String(),
+		"headers":  headerFields(req.Header),
+		"duration": duration.String(),
+	}
+
+	if t.Dump {
+		dump, _ := httputil.DumpRequestOut(req, false)
+		fields["dump"] = string(dump)
 	}
 
 	if len(body) > 0 {
-		fields["body"] = string(body)
+		fields["body"] = t.logBody(req.Header, body)
 	}
 
-	t.Logger.WithFields(fields).Debug("HTTP Request")
+	t.requestLogger(req).WithFields(fields).Debug("HTTP Request")
 }
 
-// logResponse logs the HTTP response details using the configured logger.
-func (t *loggingTransport) logResponse(resp *http.Response) {
-	dump, _ := httputil.DumpResponse(resp, false)
+// logBody returns body as logged, indented when PrettyJSON is set and header declares JSON content.
+func (t *loggingTransport) logBody(header http.Header, body []byte) string {
+	if !t.PrettyJSON || !isJSON(header.Get("Content-Type")) {
+		return string(body)
+	}
 
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		return string(body)
+	}
+	return buf.String()
+}
+
+// isJSON reports whether contentType is a JSON media type, such as application/json or
+// application/problem+json.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// logResponse logs the HTTP response details using the configured logger, at the level of its status.
+func (t *loggingTransport) logResponse(req *http.Request, resp *http.Response) {
+	// Only peek at the start of the body: it may be huge, or a small compressed
+	// payload that decompresses to gigabytes. The caller still reads all of it.
 	var body []byte
 	if resp.Body != nil {
-		body, _ = io.ReadAll(resp.Body)
-		resp.Body = io.NopCloser(bytes.NewBuffer(body))
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxLogBodySize))
+		resp.Body = &peekedBody{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
 	}
 
 	fields := map[string]any{
 		"status":  resp.Status,
-		"headers": string(dump),
+		"headers": headerFields(resp.Header),
+	}
+
+	if t.Dump {
+		dump, _ := httputil.DumpResponse(resp, false)
+		fields["dump"] = string(dump)
 	}
 
 	if len(body) > 0 {
-		fields["body"] = string(body)
+		fields["body"] = t.logBody(resp.Header, body)
+	}
+
+	logAt(t.requestLogger(req).WithFields(fields), statusLevel(resp.StatusCode), "HTTP Response")
+}
+
+// headerFields returns h as a log field, each header being a separate key so that log backends can
+// index them. Repeated headers are joined with ", ".
+func headerFields(h http.Header) map[string]string {
+	fields := make(map[string]string, len(h))
+	for k, values := range h {
+		fields[k] = strings.Join(values, ", ")
+	}
+	return fields
+}
+
+// newBaseTransport returns the innermost transport of the chain, the one that actually dials.
+// Each client gets its own clone of http.DefaultTransport so that connection-level options
+// (timeouts, dialer control, ...) never leak into other clients.
+func newBaseTransport(cfg *ClientConfig) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+	}
+	if cfg.DialTimeout > 0 {
+		dialer.Timeout = cfg.DialTimeout
+	}
+	if cfg.KeepAlive > 0 {
+		dialer.KeepAlive = cfg.KeepAlive
+	}
+	if cfg.BlockPrivateNetworks {
+		dialer.Control = denyPrivateNetworks
+	}
+	tr.DialContext = dialer.DialContext
+	if cfg.DNSCacheTTL > 0 {
+		tr.DialContext = newDNSCache(cfg.DNSCacheTTL, cfg.Clock).dialContext(dialer.DialContext)
+	}
+
+	if cfg.TLSHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		tr.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.MaxResponseHeaderBytes > 0 {
+		tr.MaxResponseHeaderBytes = cfg.MaxResponseHeaderBytes
+	}
+	if cfg.ExpectContinueTimeout > 0 {
+		tr.ExpectContinueTimeout = cfg.ExpectContinueTimeout
+	}
+	if cfg.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	tr.DisableKeepAlives = cfg.DisableKeepAlives
+
+	if len(cfg.Proxies) > 0 {
+		rotator := &proxyRotator{proxies: cfg.Proxies, strategy: cfg.ProxyRotation}
+		tr.Proxy = rotator.Proxy
+	}
+
+	if cfg.UnixSocket != "" {
+		// Every connection goes to the socket, the address derived from the URL host is ignored.
+		tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", cfg.UnixSocket)
+		}
+		tr.Proxy = nil
+	}
+
+	switch {
+	case cfg.ForceHTTP2:
+		// Already set by http.DefaultTransport, kept explicit should the default ever change.
+		tr.ForceAttemptHTTP2 = true
+	case cfg.ForceHTTP11:
+		// A non-nil, empty TLSNextProto map is how net/http is told not to upgrade to HTTP/2.
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
 	}
 
-	t.Logger.WithFields(fields).Debug("HTTP Response")
+	return tr
 }
 
 // buildTransport constructs an HTTP transport chain based on the provided client configuration.
-// It wraps http.DefaultTransport with optional layers such as header injection and request/response logging.
-//
-// Note: This implementation could be extended using a middleware-style pattern to enable
-// dynamic composition of transport behaviors, while also decoupling it from ClientConfig.
-// This would make it easier to plug in reusable layers for retries, tracing, metrics, etc...
-func buildTransport(cfg *ClientConfig) http.RoundTripper {
-	tr := http.DefaultTransport
-
-	// WARN: Apply logging as the outermost wrapper
+// It wraps the base transport with optional layers such as header injection and request/response logging,
+// and user middleware at the insertion points described by TransportLayer.
+func buildTransport(cfg *ClientConfig, base http.RoundTripper) http.RoundTripper {
+	tr := base
+
+	if cfg.AutoDecompress {
+		tr = &decompressTransport{Next: tr}
+	}
+
+	if len(cfg.AllowedHosts) > 0 || len(cfg.BlockedHosts) > 0 {
+		tr = &hostGuardTransport{
+			Next:    tr,
+			Allowed: cfg.AllowedHosts,
+			Blocked: cfg.BlockedHosts,
+		}
+	}
+
+	// Logging sits inside retries, so that every attempt is logged on its own.
 	tr = &loggingTransport{
-		Next:   tr,
-		Logger: cfg.Logger,
-		Debug:  cfg.Debug,
+		Next:          tr,
+		Logger:        cfg.logger(),
+		Debug:         cfg.Debug,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		Clock:         cfg.Clock,
+		Dump:          cfg.DebugDump,
+		PrettyJSON:    cfg.PrettyJSONLogs,
 	}
 
+	if cfg.health != nil {
+		tr = &healthTransport{
+			Next:   tr,
+			Health: cfg.health,
+		}
+	}
+
+	if cfg.Metrics != nil {
+		tr = &metricsTransport{
+			Next:     tr,
+			Recorder: cfg.Metrics,
+			Clock:    cfg.Clock,
+		}
+	}
+
+	tr = wrapMiddlewares(tr, cfg.Middlewares[AfterRetry])
+
+	if cfg.AdaptiveRateLimit {
+		tr = &rateLimitTransport{
+			Next:  tr,
+			Clock: cfg.Clock,
+		}
+	}
+
+	tr = &retryTransport{
+		Next:      tr,
+		Retries:   cfg.RetryAttempts,
+		BaseDelay: defaultRetryBaseDelay,
+		MaxDelay:  defaultRetryMaxDelay,
+		MinBudget: minRetryBudget,
+		Clock:     cfg.Clock,
+		RetryOn:   cfg.RetryOn,
+		Methods:   cfg.RetryMethods,
+
+		IdempotencyHeader: cfg.IdempotencyKeyHeader,
+		Logger:            cfg.logger(),
+	}
+
+	tr = wrapMiddlewares(tr, cfg.Middlewares[BeforeRetry])
+
 	tr = &headersTransport{
-		Next:    tr,
-		Headers: cfg.Headers,
+		Next:      tr,
+		Headers:   cfg.Headers,
+		UserAgent: cfg.DynamicUserAgent,
 	}
 
+	return wrapMiddlewares(tr, cfg.Middlewares[Outermost])
+}
+
+// wrapMiddlewares wraps tr with mws, the first one ending up outermost.
+func wrapMiddlewares(tr http.RoundTripper, mws []TransportMiddleware) http.RoundTripper {
+	for _, mw := range slices.Backward(mws) {
+		if next := mw(tr); next != nil {
+			tr = next
+		}
+	}
 	return tr
 }
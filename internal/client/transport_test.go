@@ -0,0 +1,839 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glwbr/brisa/pkg/logger"
+)
+
+// logEntry is a single log call captured by recordingLogger.
+type logEntry struct {
+	Level  logger.Level
+	Msg    string
+	Fields map[string]any
+}
+
+// recordingLogger is a logger.Logger that captures entries for assertions.
+type recordingLogger struct {
+	mu      *sync.Mutex
+	entries *[]logEntry
+	fields  map[string]any
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{mu: &sync.Mutex{}, entries: &[]logEntry{}}
+}
+
+func (l *recordingLogger) log(level logger.Level, msg string, args ...any) {
+	fields := maps.Clone(l.fields)
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		if k, ok := args[i].(string); ok {
+			fields[k] = args[i+1]
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.entries = append(*l.entries, logEntry{Level: level, Msg: msg, Fields: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.log(logger.DebugLevel, msg, args...) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.log(logger.InfoLevel, msg, args...) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.log(logger.WarnLevel, msg, args...) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.log(logger.ErrorLevel, msg, args...) }
+
+func (l *recordingLogger) WithContext(_ context.Context) logger.Logger { return l }
+
+func (l *recordingLogger) WithField(key string, value any) logger.Logger {
+	return l.WithFields(map[string]any{key: value})
+}
+
+func (l *recordingLogger) WithFields(fields map[string]any) logger.Logger {
+	merged := maps.Clone(l.fields)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	maps.Copy(merged, fields)
+	return &recordingLogger{mu: l.mu, entries: l.entries, fields: merged}
+}
+
+// Entries returns a snapshot of the captured entries.
+func (l *recordingLogger) Entries() []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logEntry(nil), *l.entries...)
+}
+
+// roundTripFunc adapts a function to the http.RoundTripper interface.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// stringResponse builds a minimal response with the given status and body.
+func stringResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestLoggingTransport_ContextFields(t *testing.T) {
+	rec := newRecordingLogger()
+	tr := &loggingTransport{
+		Next:   roundTripFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, "ok"), nil }),
+		Logger: rec,
+		Debug:  true,
+	}
+
+	ctx := logger.ContextWithFields(context.Background(), map[string]any{"user_id": 42})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+
+	if _, err := tr.RoundTrip(req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Fields["user_id"] != 42 {
+			t.Errorf("Entry %q missing user_id field: %v", e.Msg, e.Fields)
+		}
+	}
+}
+
+func TestLoggingTransport_ContextLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctxLogger  bool
+		wantClient int
+		wantCtx    int
+	}{
+		{name: "client logger without a context logger", wantClient: 2},
+		{name: "context logger preferred", ctxLogger: true, wantCtx: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientLog, ctxLog := newRecordingLogger(), newRecordingLogger()
+			tr := &loggingTransport{
+				Next:   roundTripFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, "ok"), nil }),
+				Logger: clientLog,
+				Debug:  true,
+			}
+
+			ctx := logger.ContextWithFields(context.Background(), map[string]any{"user_id": 42})
+			if tt.ctxLogger {
+				ctx = logger.ContextWithLogger(ctx, ctxLog.WithField("trace_id", "abc"))
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+
+			if _, err := tr.RoundTrip(req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := len(clientLog.Entries()); got != tt.wantClient {
+				t.Errorf("Expected %d entries in the client logger, got %d", tt.wantClient, got)
+			}
+			entries := ctxLog.Entries()
+			if len(entries) != tt.wantCtx {
+				t.Fatalf("Expected %d entries in the context logger, got %d", tt.wantCtx, len(entries))
+			}
+			for _, e := range entries {
+				if e.Fields["trace_id"] != "abc" || e.Fields["user_id"] != 42 {
+					t.Errorf("Entry %q missing the context logger or context fields: %v", e.Msg, e.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingTransport_StructuredHeaders(t *testing.T) {
+	for _, dump := range []bool{false, true} {
+		rec := newRecordingLogger()
+		tr := &loggingTransport{
+			Next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				resp := stringResponse(http.StatusOK, "ok")
+				resp.Header.Add("Set-Cookie", "a=1")
+				resp.Header.Add("Set-Cookie", "b=2")
+				return resp, nil
+			}),
+			Logger: rec,
+			Debug:  true,
+			Dump:   dump,
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		req.Header.Set("X-Request-Id", "abc")
+		if _, err := tr.RoundTrip(req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		entries := rec.Entries()
+		reqHeaders, _ := entries[0].Fields["headers"].(map[string]string)
+		respHeaders, _ := entries[1].Fields["headers"].(map[string]string)
+		if reqHeaders["X-Request-Id"] != "abc" {
+			t.Errorf("Got request headers %v, want X-Request-Id", entries[0].Fields["headers"])
+		}
+		if respHeaders["Set-Cookie"] != "a=1, b=2" {
+			t.Errorf("Got response headers %v, want joined Set-Cookie", entries[1].Fields["headers"])
+		}
+
+		for _, e := range entries {
+			if _, ok := e.Fields["dump"]; ok != dump {
+				t.Errorf("Entry %q has dump %v, want %v", e.Msg, ok, dump)
+			}
+		}
+	}
+}
+
+func TestLoggingTransport_PrettyJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		pretty      bool
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "disabled", contentType: "application/json", body: `{"a":1}`, want: `{"a":1}`},
+		{name: "json", pretty: true, contentType: "application/json; charset=utf-8", body: `{"a":[1,2]}`, want: "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{name: "json suffix", pretty: true, contentType: "application/problem+json", body: `{"a":1}`, want: "{\n  \"a\": 1\n}"},
+		{name: "invalid json", pretty: true, contentType: "application/json", body: `{"a":`, want: `{"a":`},
+		{name: "not json", pretty: true, contentType: "text/plain", body: `{"a":1}`, want: `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecordingLogger()
+			tr := &loggingTransport{
+				Next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+					resp := stringResponse(http.StatusOK, tt.body)
+					resp.Header.Set("Content-Type", tt.contentType)
+					return resp, nil
+				}),
+				Logger:     rec,
+				Debug:      true,
+				PrettyJSON: tt.pretty,
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "https://example.com", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := tr.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.body {
+				t.Errorf("Got response body %q, want it unchanged", body)
+			}
+			for _, e := range rec.Entries() {
+				if e.Fields["body"] != tt.want {
+					t.Errorf("Entry %q logged body %q, want %q", e.Msg, e.Fields["body"], tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingTransport_SlowRequests(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay time.Duration
+		want  int
+	}{
+		{name: "fast", delay: time.Second, want: 0},
+		{name: "slow", delay: 30 * time.Second, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecordingLogger()
+			clock := &fakeClock{now: time.Now()}
+			tr := &loggingTransport{
+				Next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					clock.Sleep(req.Context(), tt.delay)
+					return stringResponse(http.StatusOK, "ok"), nil
+				}),
+				Logger:        rec,
+				SlowThreshold: 10 * time.Second,
+				Clock:         clock,
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com/slow", nil)
+			if _, err := tr.RoundTrip(req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			entries := rec.Entries()
+			if len(entries) != tt.want {
+				t.Fatalf("Expected %d log entries, got %d", tt.want, len(entries))
+			}
+			for _, e := range entries {
+				if e.Level != logger.WarnLevel {
+					t.Errorf("Level = %v, want Warn", e.Level)
+				}
+				if e.Fields["method"] != http.MethodGet || e.Fields["url"] != "https://example.com/slow" || e.Fields["duration"] == nil {
+					t.Errorf("Missing request details: %v", e.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingTransport_Levels(t *testing.T) {
+	tests := []struct {
+		name   string
+		debug  bool
+		status int
+		err    error
+		want   []logger.Level
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "client error", status: http.StatusNotFound, want: []logger.Level{logger.WarnLevel}},
+		{name: "server error", status: http.StatusInternalServerError, want: []logger.Level{logger.ErrorLevel}},
+		{name: "transport error", err: errors.New("connection refused"), want: []logger.Level{logger.ErrorLevel}},
+		{name: "debug success", debug: true, status: http.StatusOK, want: []logger.Level{logger.DebugLevel, logger.DebugLevel}},
+		{name: "debug redirect", debug: true, status: http.StatusFound, want: []logger.Level{logger.DebugLevel, logger.DebugLevel}},
+		{name: "debug client error", debug: true, status: http.StatusNotFound, want: []logger.Level{logger.DebugLevel, logger.WarnLevel}},
+		{name: "debug server error", debug: true, status: http.StatusBadGateway, want: []logger.Level{logger.DebugLevel, logger.ErrorLevel}},
+		{name: "debug transport error", debug: true, err: errors.New("connection refused"), want: []logger.Level{logger.DebugLevel, logger.ErrorLevel}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecordingLogger()
+			tr := &loggingTransport{
+				Next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return stringResponse(tt.status, "body"), nil
+				}),
+				Logger: rec,
+				Debug:  tt.debug,
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com/levels", nil)
+			resp, _ := tr.RoundTrip(req)
+			DrainAndClose(resp)
+
+			entries := rec.Entries()
+			if len(entries) != len(tt.want) {
+				t.Fatalf("Expected %d log entries, got %d", len(tt.want), len(entries))
+			}
+			for i, e := range entries {
+				if e.Level != tt.want[i] {
+					t.Errorf("Entry %d (%q) level = %v, want %v", i, e.Msg, e.Level, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CanceledRequestLogging(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		close(started)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	rec := newRecordingLogger()
+	c, err := New(
+		WithBaseURL(srv.URL),
+		WithDebug(true),
+		WithLogger(rec),
+		WithHeaders(map[string]string{"X-Test": "1"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	resp, err := c.Post(ctx, "/", &RequestConfig{Body: strings.NewReader("payload")})
+	if resp != nil {
+		t.Errorf("Expected no response, got status %d", resp.StatusCode)
+	}
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a canceled error, got %v", err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d: %v", len(entries), entries)
+	}
+	if e := entries[0]; e.Msg != "HTTP Request canceled" || e.Fields["method"] != http.MethodPost || e.Fields["error"] == nil {
+		t.Errorf("Unexpected log entry: %+v", e)
+	}
+}
+
+func TestBuildTransport_Middlewares(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) TransportMiddleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name+":"+req.Header.Get("X-Default"))
+				mu.Unlock()
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	var attempts atomic.Int32
+	cfg := buildConfig(
+		WithHeaders(map[string]string{"X-Default": "set"}),
+		WithRetryAttempts(1),
+		WithClock(&fakeClock{now: time.Now()}),
+		WithTransportMiddlewareAt(AfterRetry, record("after-retry")),
+		WithTransportMiddlewareAt(BeforeRetry, record("before-retry")),
+		WithTransportMiddleware(record("outer-1")),
+		WithTransportMiddleware(record("outer-2")),
+	)
+	tr := buildTransport(cfg, statusSequence(&attempts, http.StatusServiceUnavailable, http.StatusOK))
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if _, err := tr.RoundTrip(req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"outer-1:", "outer-2:", "before-retry:set", "after-retry:set", "after-retry:set"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("Got calls %v, want %v", calls, want)
+	}
+}
+
+func TestClient_LoggerFields(t *testing.T) {
+	rec := newRecordingLogger()
+	doer := roundTripFunc(func(*http.Request) (*http.Response, error) { return stringResponse(http.StatusOK, "ok"), nil })
+
+	c, err := New(
+		WithHTTPClient(&http.Client{Transport: doer}),
+		WithLogger(rec),
+		WithDebug(true),
+		WithLoggerFields(map[string]any{"service": "billing"}),
+		WithLoggerFields(map[string]any{"component": "http"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := logger.ContextWithFields(context.Background(), map[string]any{"user_id": 42})
+	if _, err := c.Get(ctx, "https://example.com", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) == 0 {
+		t.Fatalf("Expected log entries")
+	}
+	for _, e := range entries {
+		if e.Fields["service"] != "billing" || e.Fields["component"] != "http" || e.Fields["user_id"] != 42 {
+			t.Errorf("Entry %q missing static or context fields: %v", e.Msg, e.Fields)
+		}
+	}
+	if len(rec.fields) != 0 {
+		t.Errorf("Expected the provided logger to be left untouched, got fields %v", rec.fields)
+	}
+}
+
+func TestNewBaseTransport_Timeouts(t *testing.T) {
+	cfg := buildConfig(
+		WithTLSHandshakeTimeout(2*time.Second),
+		WithResponseHeaderTimeout(3*time.Second),
+	)
+
+	tr := newBaseTransport(cfg)
+
+	if tr == http.DefaultTransport {
+		t.Fatalf("Expected a dedicated transport, got http.DefaultTransport")
+	}
+	if tr.TLSHandshakeTimeout != 2*time.Second {
+		t.Errorf("TLSHandshakeTimeout = %s, want 2s", tr.TLSHandshakeTimeout)
+	}
+	if tr.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("ResponseHeaderTimeout = %s, want 3s", tr.ResponseHeaderTimeout)
+	}
+}
+
+func TestNewBaseTransport_TransportTimeouts(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		tr := newBaseTransport(buildConfig(WithTransportTimeouts(DefaultTransportTimeouts())))
+		def := http.DefaultTransport.(*http.Transport)
+
+		if tr.TLSHandshakeTimeout != def.TLSHandshakeTimeout {
+			t.Errorf("TLSHandshakeTimeout = %s, want %s", tr.TLSHandshakeTimeout, def.TLSHandshakeTimeout)
+		}
+		if tr.ExpectContinueTimeout != def.ExpectContinueTimeout {
+			t.Errorf("ExpectContinueTimeout = %s, want %s", tr.ExpectContinueTimeout, def.ExpectContinueTimeout)
+		}
+		if tr.IdleConnTimeout != def.IdleConnTimeout {
+			t.Errorf("IdleConnTimeout = %s, want %s", tr.IdleConnTimeout, def.IdleConnTimeout)
+		}
+		if tr.ResponseHeaderTimeout != 0 {
+			t.Errorf("ResponseHeaderTimeout = %s, want none", tr.ResponseHeaderTimeout)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		cfg := buildConfig(
+			WithTLSHandshakeTimeout(2*time.Second),
+			WithTransportTimeouts(TransportTimeouts{
+				DialTimeout:           time.Second,
+				KeepAlive:             time.Minute,
+				ExpectContinueTimeout: 3 * time.Second,
+				ResponseHeaderTimeout: 4 * time.Second,
+				IdleConnTimeout:       5 * time.Second,
+			}),
+		)
+		if cfg.DialTimeout != time.Second || cfg.KeepAlive != time.Minute {
+			t.Errorf("Got dial timeout %s and keep-alive %s, want 1s and 1m", cfg.DialTimeout, cfg.KeepAlive)
+		}
+
+		tr := newBaseTransport(cfg)
+		if tr.TLSHandshakeTimeout != 2*time.Second {
+			t.Errorf("TLSHandshakeTimeout = %s, want the 2s set before", tr.TLSHandshakeTimeout)
+		}
+		if tr.ExpectContinueTimeout != 3*time.Second {
+			t.Errorf("ExpectContinueTimeout = %s, want 3s", tr.ExpectContinueTimeout)
+		}
+		if tr.ResponseHeaderTimeout != 4*time.Second {
+			t.Errorf("ResponseHeaderTimeout = %s, want 4s", tr.ResponseHeaderTimeout)
+		}
+		if tr.IdleConnTimeout != 5*time.Second {
+			t.Errorf("IdleConnTimeout = %s, want 5s", tr.IdleConnTimeout)
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := New(
+			WithStrictValidation(),
+			WithTransportTimeouts(TransportTimeouts{DialTimeout: time.Second, IdleConnTimeout: -time.Second}),
+		)
+		if err == nil {
+			t.Errorf("Expected an error for a negative timeout")
+		}
+	})
+}
+
+func TestClient_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, err := New(WithNoTimeout(), WithResponseHeaderTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = c.Get(context.Background(), srv.URL, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestClient_MaxResponseHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := range 10 {
+			w.Header().Add("X-Filler", strings.Repeat("x", 100)+strconv.Itoa(i))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		opt      ClientOption
+		wantFail bool
+		wantErr  error
+	}{
+		{name: "within limits", opt: WithMaxResponseHeaders(20)},
+		{name: "too many headers", opt: WithMaxResponseHeaders(5), wantFail: true, wantErr: ErrTooManyHeaders},
+		{name: "headers too large", opt: WithMaxResponseHeaderBytes(256), wantFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.opt)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Get(context.Background(), srv.URL, nil)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantFail {
+				t.Fatalf("Got error %v, want failure %v", err, tt.wantFail)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClient_DisableKeepAlives(t *testing.T) {
+	tests := []struct {
+		name      string
+		disable   bool
+		wantConns int64
+	}{
+		{name: "keep-alives enabled", disable: false, wantConns: 1},
+		{name: "keep-alives disabled", disable: true, wantConns: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conns atomic.Int64
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "ok")
+			}))
+			srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					conns.Add(1)
+				}
+			}
+			srv.Start()
+			defer srv.Close()
+
+			c, err := New(WithDisableKeepAlives(tt.disable))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for range 3 {
+				resp, err := c.Get(context.Background(), srv.URL, nil)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				DrainAndClose(resp)
+			}
+
+			if got := conns.Load(); got != tt.wantConns {
+				t.Errorf("Opened %d connections, want %d", got, tt.wantConns)
+			}
+		})
+	}
+}
+
+func TestNewBaseTransport_HTTPVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		wantMajor int
+	}{
+		{name: "default", wantMajor: 2},
+		{name: "force HTTP/2", opts: []ClientOption{WithForceHTTP2()}, wantMajor: 2},
+		{name: "force HTTP/1.1", opts: []ClientOption{WithForceHTTP11()}, wantMajor: 1},
+		{name: "last option wins", opts: []ClientOption{WithForceHTTP11(), WithForceHTTP2()}, wantMajor: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newBaseTransport(buildConfig(tt.opts...))
+			tr.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			defer tr.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.ProtoMajor != tt.wantMajor {
+				t.Errorf("Got protocol %s, want major version %d", resp.Proto, tt.wantMajor)
+			}
+		})
+	}
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "brisa")
+	if err != nil {
+		t.Fatalf("Failed to create socket directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "api.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+
+	var gotHost, gotPath string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotPath = r.Host, r.URL.Path
+		io.WriteString(w, "[]")
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	c, err := New(WithBaseURL("http://unix"), WithUnixSocket(socket))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "/v1.40/containers/json", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "[]" {
+		t.Errorf("Got body %q, want %q", body, "[]")
+	}
+	if gotHost != "unix" || gotPath != "/v1.40/containers/json" {
+		t.Errorf("Got host %q and path %q, want the placeholder host and the request path", gotHost, gotPath)
+	}
+
+	if _, err := New(WithUnixSocket(socket), WithBlockPrivateNetworks()); err == nil {
+		t.Errorf("Expected an error combining WithUnixSocket and WithBlockPrivateNetworks")
+	}
+}
+
+func TestClient_ClearDefaultHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		wantUA    []string
+		wantToken string
+	}{
+		{name: "default", wantUA: []string{defaultUserAgent}},
+		{
+			name:   "cleared",
+			opts:   []ClientOption{WithHeaders(map[string]string{"X-Token": "abc"}), WithClearDefaultHeaders()},
+			wantUA: nil,
+		},
+		{
+			name:      "cleared then set",
+			opts:      []ClientOption{WithClearDefaultHeaders(), WithHeaders(map[string]string{"X-Token": "abc", "User-Agent": "mine/1.0"})},
+			wantUA:    []string{"mine/1.0"},
+			wantToken: "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUA []string
+			var gotToken string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUA, gotToken = r.Header.Values("User-Agent"), r.Header.Get("X-Token")
+			}))
+			defer srv.Close()
+
+			c, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resp, err := c.Get(context.Background(), srv.URL, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if !slices.Equal(gotUA, tt.wantUA) {
+				t.Errorf("Got User-Agent %q, want %q", gotUA, tt.wantUA)
+			}
+			if gotToken != tt.wantToken {
+				t.Errorf("Got X-Token %q, want %q", gotToken, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestClient_DynamicUserAgent(t *testing.T) {
+	type tenantKey struct{}
+	tenantUA := func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		tenant  string
+		headers map[string]string
+		want    string
+	}{
+		{name: "without dynamic user agent", tenant: "acme", want: "base/1.0"},
+		{name: "appended", opts: []ClientOption{WithDynamicUserAgent(tenantUA)}, tenant: "acme", want: "base/1.0 acme"},
+		{name: "empty suffix", opts: []ClientOption{WithDynamicUserAgent(tenantUA)}, want: "base/1.0"},
+		{
+			name:    "explicit request user agent",
+			opts:    []ClientOption{WithDynamicUserAgent(tenantUA)},
+			tenant:  "acme",
+			headers: map[string]string{"User-Agent": "custom/2.0"},
+			want:    "custom/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+			}))
+			defer srv.Close()
+
+			opts := append([]ClientOption{WithHeaders(map[string]string{"User-Agent": "base/1.0"})}, tt.opts...)
+			c, err := New(opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			ctx := context.WithValue(context.Background(), tenantKey{}, tt.tenant)
+			resp, err := c.Get(ctx, srv.URL, &RequestConfig{Headers: tt.headers})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/glwbr/brisa/pkg/errors"
+)
+
+// UploadProgress is called as the body of an upload is sent, with the number of bytes sent so far
+// and the total size of the upload, -1 when unknown.
+type UploadProgress func(sent, total int64)
+
+// Upload sends a POST request streaming f, from its current offset, as the request body. The body is
+// never buffered in memory, so it is suitable for large files. The caller keeps ownership of f and
+// closes it once Upload returns.
+//
+// For regular files, Content-Length is set from the file size. progress, when not nil, is called
+// after each chunk read from f, e.g. to drive a progress bar. When the request is retried or
+// redirected, f is read again from the start and sent goes back to 0.
+//
+// Reading stops as soon as ctx is done, aborting the request.
+func (c *Client) Upload(ctx context.Context, path string, f *os.File, opts *RequestConfig, progress UploadProgress) (*http.Response, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect upload file")
+	}
+
+	body := &progressReader{ctx: ctx, file: f, total: -1, progress: progress}
+	if info.Mode().IsRegular() {
+		if body.start, err = f.Seek(0, io.SeekCurrent); err != nil {
+			return nil, errors.Wrap(err, "failed to inspect upload file")
+		}
+		body.total = max(info.Size()-body.start, 0)
+	}
+
+	// Work on a copy, leaving the caller's config untouched.
+	cfg := RequestConfig{}
+	if opts != nil {
+		cfg = *opts
+	}
+	cfg.Body, cfg.BodyBytes, cfg.GetBody = body, nil, nil
+
+	return c.do(ctx, http.MethodPost, path, &cfg)
+}
+
+// progressReader reads an upload file, reporting progress and stopping once its context is done.
+// It is seekable whenever the file is, so that the request can be rewound and its length known.
+type progressReader struct {
+	ctx      context.Context
+	file     *os.File
+	start    int64
+	sent     int64
+	total    int64
+	progress UploadProgress
+}
+
+// Read implements the io.Reader interface.
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.file.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		if r.progress != nil {
+			r.progress(r.sent, r.total)
+		}
+	}
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.file.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+
+	r.sent = pos - r.start
+	return pos, nil
+}
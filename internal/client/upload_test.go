@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_Upload(t *testing.T) {
+	content := bytes.Repeat([]byte("artifact"), 64<<10)
+
+	var gotLength int64
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLength = r.ContentLength
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "artifact.bin")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("Failed to write upload file: %v", err)
+	}
+
+	c, err := New(WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("streams file with progress", func(t *testing.T) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open upload file: %v", err)
+		}
+		defer f.Close()
+
+		var calls int
+		var lastSent, lastTotal int64
+		progress := func(sent, total int64) {
+			if sent < lastSent {
+				t.Errorf("Progress went back from %d to %d", lastSent, sent)
+			}
+			calls++
+			lastSent, lastTotal = sent, total
+		}
+
+		resp, err := c.Upload(context.Background(), "/upload", f, nil, progress)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		DrainAndClose(resp)
+
+		if gotLength != int64(len(content)) {
+			t.Errorf("Got Content-Length %d, want %d", gotLength, len(content))
+		}
+		if !bytes.Equal(gotBody, content) {
+			t.Errorf("Got a %d byte body, want the %d byte file", len(gotBody), len(content))
+		}
+		if calls < 2 {
+			t.Errorf("Expected progress to be reported several times, got %d calls", calls)
+		}
+		if lastSent != int64(len(content)) || lastTotal != int64(len(content)) {
+			t.Errorf("Got final progress %d/%d, want %d/%d", lastSent, lastTotal, len(content), len(content))
+		}
+	})
+
+	t.Run("cancellation aborts the upload", func(t *testing.T) {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open upload file: %v", err)
+		}
+		defer f.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var lastSent int64
+		progress := func(sent, total int64) {
+			lastSent = sent
+			cancel()
+		}
+
+		_, err = c.Upload(ctx, "/upload", f, nil, progress)
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+		if lastSent >= int64(len(content)) {
+			t.Errorf("Expected the upload to stop early, sent %d bytes", lastSent)
+		}
+	})
+}
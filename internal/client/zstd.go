@@ -0,0 +1,25 @@
+//go:build zstd
+
+package client
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdWindowSize bounds the memory a zstd frame may require to be decoded, as servers choose the
+// window size. 8MB is the limit RFC 8878 sets for the "zstd" content coding.
+const zstdWindowSize = 8 << 20
+
+// Registers the "zstd" content coding for WithAutoDecompress, only available when building with the
+// "zstd" tag.
+func init() {
+	registerContentCoding("zstd", func(r io.Reader) (io.ReadCloser, error) {
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(zstdWindowSize))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	})
+}
@@ -0,0 +1,55 @@
+//go:build zstd
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestClient_AutoDecompress_Zstd(t *testing.T) {
+	body := strings.Repeat("compressible ", 100)
+
+	var gotAccept string
+	srv := httptest.NewServer(compressedHandler(t, body, map[string]func(io.Writer) io.WriteCloser{
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			if err != nil {
+				t.Fatalf("Failed to create zstd writer: %v", err)
+			}
+			return zw
+		},
+	}, &gotAccept))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithAutoDecompress())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := c.Get(context.Background(), "/", &RequestConfig{Params: url.Values{"coding": {"zstd"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if gotAccept != "zstd, br, gzip, deflate" {
+		t.Errorf("Got Accept-Encoding %q, want zstd preferred", gotAccept)
+	}
+	if string(got) != body {
+		t.Errorf("Got a %d byte body, want the %d byte decoded one", len(got), len(body))
+	}
+	if coding := resp.Header.Get("Content-Encoding"); coding != "" {
+		t.Errorf("Got Content-Encoding %q, want it removed", coding)
+	}
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// fieldsKey is the context key under which request-scoped fields are stored.
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying the given fields, merged on top of
+// any fields already stored in ctx. Consumers such as the HTTP client's logging transport
+// attach these fields to every log line produced while handling that context.
+// The fields map is copied, so subsequent changes to the original won't affect the context.
+func ContextWithFields(ctx context.Context, fields map[string]any) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	merged := make(map[string]any, len(fields))
+	if existing, ok := ctx.Value(fieldsKey{}).(map[string]any); ok {
+		maps.Copy(merged, existing)
+	}
+	maps.Copy(merged, fields)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stored in ctx by ContextWithFields, or nil if there are none.
+// The returned map must not be modified.
+func FieldsFromContext(ctx context.Context) map[string]any {
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]any)
+	return fields
+}
+
+// loggerKey is the context key under which a request-scoped logger is stored.
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying l, e.g. the request-scoped logger of a web handler
+// with its trace fields. Consumers such as the HTTP client log through it instead of their own
+// logger, so their logs inherit its fields. A nil l returns ctx unchanged.
+func ContextWithLogger(ctx context.Context, l Logger) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFromContext returns the logger stored in ctx by ContextWithLogger, and whether there is one.
+func LoggerFromContext(ctx context.Context) (Logger, bool) {
+	l, ok := ctx.Value(loggerKey{}).(Logger)
+	return l, ok
+}
+
+// ContextExtractor returns the fields to log for ctx, e.g. the trace and span IDs of the tracing
+// library in use, or nil if there are none.
+type ContextExtractor func(ctx context.Context) map[string]any
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// RegisterContextExtractor teaches loggers how to pull fields out of a context: the fields fn returns
+// are part of ContextFields, attached by WithContext implementations and by the HTTP client's logging.
+// It is meant to be called during initialization, e.g. from an init function. Nil is ignored.
+func RegisterContextExtractor(fn ContextExtractor) {
+	if fn == nil {
+		return
+	}
+
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// ContextFields returns the fields to log for ctx: those returned by the registered extractors, in
+// registration order, then those stored with ContextWithFields, later ones overriding earlier ones.
+// It returns nil if there are none. Logger implementations should attach them in WithContext.
+func ContextFields(ctx context.Context) map[string]any {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	stored := FieldsFromContext(ctx)
+	if len(extractors) == 0 {
+		return stored
+	}
+
+	var fields map[string]any
+	for _, extract := range extractors {
+		if extracted := extract(ctx); len(extracted) > 0 {
+			if fields == nil {
+				fields = make(map[string]any, len(extracted)+len(stored))
+			}
+			maps.Copy(fields, extracted)
+		}
+	}
+	if fields == nil {
+		return stored
+	}
+
+	maps.Copy(fields, stored)
+	return fields
+}
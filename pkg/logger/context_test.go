@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestContextWithFields(t *testing.T) {
+	ctx := context.Background()
+
+	if got := FieldsFromContext(ctx); got != nil {
+		t.Fatalf("Expected no fields, got %v", got)
+	}
+
+	input := map[string]any{"user_id": 42}
+	ctx = ContextWithFields(ctx, input)
+	ctx = ContextWithFields(ctx, map[string]any{"request_id": "abc", "user_id": 7})
+	input["user_id"] = 0
+
+	want := map[string]any{"user_id": 7, "request_id": "abc"}
+	if got := FieldsFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestContextWithLogger(t *testing.T) {
+	ctx := context.Background()
+
+	if _, ok := LoggerFromContext(ctx); ok {
+		t.Fatalf("Expected no logger in a bare context")
+	}
+	if got := ContextWithLogger(ctx, nil); got != ctx {
+		t.Errorf("Expected a nil logger to leave the context unchanged")
+	}
+
+	want := NoOp{}
+	got, ok := LoggerFromContext(ContextWithLogger(ctx, want))
+	if !ok || got != want {
+		t.Errorf("Got %v (%t), want %v", got, ok, want)
+	}
+}
+
+// traceIDKey is the context key of the trace ID in TestContextFields.
+type traceIDKey struct{}
+
+func TestContextFields(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context) map[string]any {
+		if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+			return map[string]any{"trace_id": id, "source": "extractor"}
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	if got := ContextFields(ctx); got != nil {
+		t.Errorf("Expected no fields, got %v", got)
+	}
+
+	ctx = context.WithValue(ctx, traceIDKey{}, "t-1")
+	want := map[string]any{"trace_id": "t-1", "source": "extractor"}
+	if got := ContextFields(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	ctx = ContextWithFields(ctx, map[string]any{"source": "context"})
+	want = map[string]any{"trace_id": "t-1", "source": "context"}
+	if got := ContextFields(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// badKey is the key of a trailing argument that has no value to pair with.
+const badKey = "!BADKEY"
+
+// JSONLogger is a dependency-free Logger writing each entry as a single line of JSON, with the time,
+// level and message followed by the fields in key order:
+//
+//	{"time":"2025-01-02T15:04:05.000000006Z","level":"INFO","msg":"Request done","status":200}
+//
+// Errors are rendered with their Error method and, when formatting them with %+v tells more, such
+// as a stack trace, under an additional "<key>_stack" field. Fields named time, level or msg are
+// renamed "fields.<key>" so they don't clash with the entry's own.
+type JSONLogger struct {
+	out    *jsonOutput
+	fields map[string]any
+}
+
+// jsonOutput is the writer shared by a JSONLogger and the loggers derived from it.
+type jsonOutput struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewJSON returns a JSONLogger writing to w. Writes are serialized, so w needs no locking of its own.
+func NewJSON(w io.Writer) Logger {
+	return &JSONLogger{out: &jsonOutput{w: w, now: time.Now}}
+}
+
+// Debug logs a debug-level message with optional key-value fields.
+func (l *JSONLogger) Debug(msg string, args ...any) { l.log(DebugLevel, msg, args) }
+
+// Info logs an info-level message with optional key-value fields.
+func (l *JSONLogger) Info(msg string, args ...any) { l.log(InfoLevel, msg, args) }
+
+// Warn logs a warning-level message with optional key-value fields.
+func (l *JSONLogger) Warn(msg string, args ...any) { l.log(WarnLevel, msg, args) }
+
+// Error logs an error-level message with optional key-value fields.
+func (l *JSONLogger) Error(msg string, args ...any) { l.log(ErrorLevel, msg, args) }
+
+// WithContext returns a JSONLogger with the fields of ctx, see ContextFields.
+func (l *JSONLogger) WithContext(ctx context.Context) Logger {
+	return l.WithFields(ContextFields(ctx))
+}
+
+// WithField returns a JSONLogger with a single additional field.
+func (l *JSONLogger) WithField(key string, value any) Logger {
+	return l.WithFields(map[string]any{key: value})
+}
+
+// WithFields returns a JSONLogger with additional fields, overriding existing ones of the same key.
+func (l *JSONLogger) WithFields(fields map[string]any) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	maps.Copy(merged, l.fields)
+	maps.Copy(merged, fields)
+	return &JSONLogger{out: l.out, fields: merged}
+}
+
+// log writes an entry with the logger's fields and the key-value pairs of args.
+func (l *JSONLogger) log(level Level, msg string, args []any) {
+	fields := maps.Clone(l.fields)
+	if fields == nil {
+		fields = make(map[string]any, len(args)/2+1)
+	}
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fields[badKey] = args[i]
+			break
+		}
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		fields[key] = args[i+1]
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSONValue(&buf, l.out.now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSONValue(&buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(&buf, msg)
+
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		value := fields[key]
+		name := key
+		if key == "time" || key == "level" || key == "msg" {
+			name = "fields." + key
+		}
+
+		err, ok := value.(error)
+		if !ok || err == nil {
+			writeJSONField(&buf, name, value)
+			continue
+		}
+
+		writeJSONField(&buf, name, err.Error())
+		if verbose := fmt.Sprintf("%+v", err); verbose != err.Error() {
+			writeJSONField(&buf, name+"_stack", verbose)
+		}
+	}
+	buf.WriteString("}\n")
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	_, _ = l.out.w.Write(buf.Bytes())
+}
+
+// writeJSONField appends ,"key":value to buf.
+func writeJSONField(buf *bytes.Buffer, key string, value any) {
+	buf.WriteByte(',')
+	writeJSONValue(buf, key)
+	buf.WriteByte(':')
+	writeJSONValue(buf, value)
+}
+
+// writeJSONValue appends v encoded as JSON to buf, or its fmt representation as a JSON string if it
+// can't be encoded.
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
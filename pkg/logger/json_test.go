@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stackError is an error telling more when formatted with %+v, like errors carrying a stack trace.
+type stackError struct{ msg string }
+
+func (e stackError) Error() string { return e.msg }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\n\tmain.go:42", e.msg)
+		return
+	}
+	fmt.Fprint(s, e.msg)
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewJSON(&buf).(*JSONLogger)
+	log.out.now = func() time.Time { return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC) }
+
+	ctx := ContextWithFields(context.Background(), map[string]any{"request_id": "abc"})
+	log.WithContext(ctx).WithField("msg", "shadowed").WithFields(map[string]any{"user": "a\"b\n"}).
+		Warn("Slow \"request\"", "status", 503, ErrorKey, errors.New("boom"))
+	log.Error("Failed", "error", stackError{msg: "oops"}, "dangling")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	tests := []struct {
+		line string
+		want map[string]any
+	}{
+		{
+			line: lines[0],
+			want: map[string]any{
+				"time":       "2025-01-02T15:04:05Z",
+				"level":      "WARN",
+				"msg":        "Slow \"request\"",
+				"fields.msg": "shadowed",
+				"request_id": "abc",
+				"user":       "a\"b\n",
+				"status":     float64(503),
+				"error":      "boom",
+			},
+		},
+		{
+			line: lines[1],
+			want: map[string]any{
+				"time":        "2025-01-02T15:04:05Z",
+				"level":       "ERROR",
+				"msg":         "Failed",
+				"error":       "oops",
+				"error_stack": "oops\n\tmain.go:42",
+				badKey:        "dangling",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var got map[string]any
+		if err := json.Unmarshal([]byte(tt.line), &got); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", tt.line, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Got %v, want %v", got, tt.want)
+		}
+	}
+
+	if !strings.HasPrefix(lines[0], `{"time":`) {
+		t.Errorf("Expected the time first, got %s", lines[0])
+	}
+}
+
+func TestJSONLogger_Concurrent(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewJSON(&buf)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.WithField("worker", i).Info("Working")
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("Got %d lines, want 20", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("Interleaved or invalid line %q", line)
+		}
+	}
+}
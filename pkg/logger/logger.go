@@ -5,7 +5,11 @@
 //   - Context-aware logging
 package logger
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // Logger is the fundamental logging interface used throughout the application.
 // Implementations must be thread-safe.
@@ -64,3 +68,56 @@ func (l Level) String() string {
 		return "UNKNOWN"
 	}
 }
+
+// ParseLevel returns the level named s, one of "debug", "info", "warn" or "error", case-insensitively,
+// e.g. to configure the level from an environment variable or a flag. It is the inverse of String.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the level as its canonical name, so that a
+// Level can be used in JSON or YAML configuration structs.
+func (l Level) MarshalText() ([]byte, error) {
+	if l < DebugLevel || l > ErrorLevel {
+		return nil, fmt.Errorf("invalid log level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the names ParseLevel does,
+// e.g. "level: debug" in a configuration file.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+	return nil
+}
+
+// ErrorKey is the conventional key of the error in a log entry.
+const ErrorKey = "error"
+
+// Err returns the conventional key and value for logging err, which fits WithField:
+//
+//	log.WithField(logger.Err(err)).Error("Request failed")
+func Err(err error) (string, any) {
+	return ErrorKey, err
+}
+
+// LogError logs msg at error level to l, with err under ErrorKey followed by the key-value pairs of args.
+func LogError(l Logger, msg string, err error, args ...any) {
+	l.Error(msg, append([]any{ErrorKey, err}, args...)...)
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Level
+		wantErr bool
+	}{
+		{input: "debug", want: DebugLevel},
+		{input: "INFO", want: InfoLevel},
+		{input: " Warn ", want: WarnLevel},
+		{input: "error", want: ErrorLevel},
+		{input: "verbose", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel_RoundTrip(t *testing.T) {
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		got, err := ParseLevel(level.String())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != level {
+			t.Errorf("Got %v, want %v", got, level)
+		}
+	}
+}
+
+func TestLevel_Text(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		data, err := json.Marshal(config{Level: level})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := `{"level":"` + level.String() + `"}`; string(data) != want {
+			t.Errorf("Got %s, want %s", data, want)
+		}
+
+		var got config
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Level != level {
+			t.Errorf("Got %v, want %v", got.Level, level)
+		}
+	}
+
+	var cfg config
+	if err := json.Unmarshal([]byte(`{"level":"debug"}`), &cfg); err != nil || cfg.Level != DebugLevel {
+		t.Errorf("Got %v with error %v, want DEBUG", cfg.Level, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &cfg); err == nil {
+		t.Errorf("Expected an error for an unknown level")
+	}
+	if _, err := json.Marshal(config{Level: Level(42)}); err == nil {
+		t.Errorf("Expected an error for an invalid level")
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	err := errors.New("connection refused")
+	capture := newCaptureLogger()
+
+	capture.WithField(Err(err)).Error("Request failed")
+	LogError(capture, "Request failed", err, "attempt", 2)
+
+	entries := *capture.entries
+	if len(entries) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(entries))
+	}
+	if got := entries[0].fields[ErrorKey]; got != err {
+		t.Errorf("Got field %v, want %v", got, err)
+	}
+	if want := []any{ErrorKey, err, "attempt", 2}; !reflect.DeepEqual(entries[1].args, want) {
+		t.Errorf("Got args %v, want %v", entries[1].args, want)
+	}
+}
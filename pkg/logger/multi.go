@@ -0,0 +1,75 @@
+package logger
+
+import "context"
+
+// MultiLogger is a Logger forwarding every call to several loggers, e.g. to log both to stdout and to
+// a file or a remote sink. A logger panicking doesn't prevent the others from receiving the entry.
+type MultiLogger struct {
+	loggers []Logger
+}
+
+// NewMulti returns a Logger forwarding to loggers, in order. Nil loggers are skipped.
+func NewMulti(loggers ...Logger) Logger {
+	m := &MultiLogger{loggers: make([]Logger, 0, len(loggers))}
+	for _, l := range loggers {
+		if l != nil {
+			m.loggers = append(m.loggers, l)
+		}
+	}
+	return m
+}
+
+// Debug logs a debug-level message to every logger.
+func (m *MultiLogger) Debug(msg string, args ...any) {
+	m.each(func(l Logger) { l.Debug(msg, args...) })
+}
+
+// Info logs an info-level message to every logger.
+func (m *MultiLogger) Info(msg string, args ...any) {
+	m.each(func(l Logger) { l.Info(msg, args...) })
+}
+
+// Warn logs a warning-level message to every logger.
+func (m *MultiLogger) Warn(msg string, args ...any) {
+	m.each(func(l Logger) { l.Warn(msg, args...) })
+}
+
+// Error logs an error-level message to every logger.
+func (m *MultiLogger) Error(msg string, args ...any) {
+	m.each(func(l Logger) { l.Error(msg, args...) })
+}
+
+// WithContext returns a MultiLogger whose loggers all inherit ctx.
+func (m *MultiLogger) WithContext(ctx context.Context) Logger {
+	return m.derive(func(l Logger) Logger { return l.WithContext(ctx) })
+}
+
+// WithField returns a MultiLogger whose loggers all have the additional field.
+func (m *MultiLogger) WithField(key string, value any) Logger {
+	return m.derive(func(l Logger) Logger { return l.WithField(key, value) })
+}
+
+// WithFields returns a MultiLogger whose loggers all have the additional fields.
+func (m *MultiLogger) WithFields(fields map[string]any) Logger {
+	return m.derive(func(l Logger) Logger { return l.WithFields(fields) })
+}
+
+// each calls fn with every logger, recovering from panics so that one failing logger doesn't stop
+// the others.
+func (m *MultiLogger) each(fn func(Logger)) {
+	for _, l := range m.loggers {
+		func() {
+			defer func() { _ = recover() }()
+			fn(l)
+		}()
+	}
+}
+
+// derive returns a MultiLogger of the loggers returned by fn for each logger.
+func (m *MultiLogger) derive(fn func(Logger) Logger) Logger {
+	derived := &MultiLogger{loggers: make([]Logger, len(m.loggers))}
+	for i, l := range m.loggers {
+		derived.loggers[i] = fn(l)
+	}
+	return derived
+}
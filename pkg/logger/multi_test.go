@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"testing"
+)
+
+// entry is a log call captured by captureLogger.
+type entry struct {
+	level  Level
+	msg    string
+	args   []any
+	fields map[string]any
+}
+
+// captureLogger records the entries logged through it and the loggers derived from it.
+type captureLogger struct {
+	entries *[]entry
+	fields  map[string]any
+}
+
+func newCaptureLogger() *captureLogger {
+	return &captureLogger{entries: &[]entry{}, fields: map[string]any{}}
+}
+
+func (l *captureLogger) log(level Level, msg string, args []any) {
+	*l.entries = append(*l.entries, entry{level: level, msg: msg, args: args, fields: maps.Clone(l.fields)})
+}
+
+func (l *captureLogger) Debug(msg string, args ...any) { l.log(DebugLevel, msg, args) }
+func (l *captureLogger) Info(msg string, args ...any)  { l.log(InfoLevel, msg, args) }
+func (l *captureLogger) Warn(msg string, args ...any)  { l.log(WarnLevel, msg, args) }
+func (l *captureLogger) Error(msg string, args ...any) { l.log(ErrorLevel, msg, args) }
+
+func (l *captureLogger) WithContext(_ context.Context) Logger { return l }
+
+func (l *captureLogger) WithField(key string, value any) Logger {
+	return l.WithFields(map[string]any{key: value})
+}
+
+func (l *captureLogger) WithFields(fields map[string]any) Logger {
+	merged := maps.Clone(l.fields)
+	maps.Copy(merged, fields)
+	return &captureLogger{entries: l.entries, fields: merged}
+}
+
+// panicLogger panics on every log call.
+type panicLogger struct{ NoOp }
+
+func (panicLogger) Info(string, ...any) { panic("sink unavailable") }
+
+func TestMultiLogger(t *testing.T) {
+	first, second := newCaptureLogger(), newCaptureLogger()
+	log := NewMulti(first, nil, panicLogger{}, second)
+
+	log.WithField("request_id", "abc").WithContext(context.Background()).Info("Request done")
+	log.Warn("Slow")
+
+	want := []entry{
+		{level: InfoLevel, msg: "Request done", fields: map[string]any{"request_id": "abc"}},
+		{level: WarnLevel, msg: "Slow", fields: map[string]any{}},
+	}
+	for i, l := range []*captureLogger{first, second} {
+		if !reflect.DeepEqual(*l.entries, want) {
+			t.Errorf("Logger %d got %v, want %v", i, *l.entries, want)
+		}
+	}
+}
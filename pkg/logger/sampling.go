@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// samplingInterval is the window over which SamplingLogger counts messages.
+const samplingInterval = time.Second
+
+// SamplingLogger is a Logger wrapper keeping log volume bounded under load: it logs the first messages
+// of each level every second, up to a limit, and drops the rest. How many were dropped is reported by
+// a "Dropped log messages" entry of the same level once the next message of that level is logged in a
+// later window.
+//
+// Loggers derived with WithContext, WithField and WithFields share the limits of their parent.
+type SamplingLogger struct {
+	next    Logger
+	sampler *sampler
+}
+
+// sampler counts the messages of each level logged in the current window.
+type sampler struct {
+	perSecond int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	windows [ErrorLevel + 1]samplingWindow
+}
+
+// samplingWindow holds the counts of a level for the window started at start.
+type samplingWindow struct {
+	start   time.Time
+	logged  int
+	dropped int
+}
+
+// NewSampling returns a Logger logging at most perSecond messages of each level per second to
+// underlying. A perSecond <= 0 disables sampling: underlying is returned as is.
+func NewSampling(underlying Logger, perSecond int) Logger {
+	if perSecond <= 0 {
+		return underlying
+	}
+	return &SamplingLogger{next: underlying, sampler: &sampler{perSecond: perSecond, now: time.Now}}
+}
+
+// Debug logs a debug-level message unless the limit of the current window is reached.
+func (s *SamplingLogger) Debug(msg string, args ...any) {
+	s.log(DebugLevel, s.next.Debug, msg, args)
+}
+
+// Info logs an info-level message unless the limit of the current window is reached.
+func (s *SamplingLogger) Info(msg string, args ...any) {
+	s.log(InfoLevel, s.next.Info, msg, args)
+}
+
+// Warn logs a warning-level message unless the limit of the current window is reached.
+func (s *SamplingLogger) Warn(msg string, args ...any) {
+	s.log(WarnLevel, s.next.Warn, msg, args)
+}
+
+// Error logs an error-level message unless the limit of the current window is reached.
+func (s *SamplingLogger) Error(msg string, args ...any) {
+	s.log(ErrorLevel, s.next.Error, msg, args)
+}
+
+// WithContext returns a SamplingLogger whose underlying logger inherits ctx.
+func (s *SamplingLogger) WithContext(ctx context.Context) Logger {
+	return &SamplingLogger{next: s.next.WithContext(ctx), sampler: s.sampler}
+}
+
+// WithField returns a SamplingLogger whose underlying logger has the additional field.
+func (s *SamplingLogger) WithField(key string, value any) Logger {
+	return &SamplingLogger{next: s.next.WithField(key, value), sampler: s.sampler}
+}
+
+// WithFields returns a SamplingLogger whose underlying logger has the additional fields.
+func (s *SamplingLogger) WithFields(fields map[string]any) Logger {
+	return &SamplingLogger{next: s.next.WithFields(fields), sampler: s.sampler}
+}
+
+// log sends msg to logFn when the sampler allows it, preceded by the summary of the previous window.
+func (s *SamplingLogger) log(level Level, logFn func(string, ...any), msg string, args []any) {
+	ok, dropped := s.sampler.allow(level)
+	if dropped > 0 {
+		logFn("Dropped log messages", "dropped", dropped, "interval", samplingInterval.String())
+	}
+	if ok {
+		logFn(msg, args...)
+	}
+}
+
+// allow reports whether a message of level can be logged in the current window, and the number of
+// messages dropped in the previous window of that level when this one starts a new window.
+func (s *sampler) allow(level Level) (ok bool, dropped int) {
+	if level < DebugLevel || level > ErrorLevel {
+		return true, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	w := &s.windows[level]
+	if now.Sub(w.start) >= samplingInterval {
+		dropped = w.dropped
+		*w = samplingWindow{start: now}
+	}
+
+	if w.logged < s.perSecond {
+		w.logged++
+		return true, dropped
+	}
+
+	w.dropped++
+	return false, dropped
+}
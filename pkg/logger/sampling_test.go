@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplingLogger(t *testing.T) {
+	capture := newCaptureLogger()
+	log := NewSampling(capture, 2).(*SamplingLogger)
+
+	now := time.Now()
+	log.sampler.now = func() time.Time { return now }
+
+	for range 5 {
+		log.Info("Request done")
+	}
+	log.WithField("request_id", "abc").Info("Request done")
+	log.Error("Request failed")
+
+	if got := len(*capture.entries); got != 3 {
+		t.Fatalf("Got %d entries, want 2 info and 1 error: %v", got, *capture.entries)
+	}
+
+	now = now.Add(time.Second)
+	log.Info("Request done")
+
+	entries := *capture.entries
+	if len(entries) != 5 {
+		t.Fatalf("Got %d entries, want a summary and the new message: %v", len(entries), entries)
+	}
+	if summary := entries[3]; summary.msg != "Dropped log messages" || summary.level != InfoLevel ||
+		len(summary.args) < 2 || summary.args[1] != 4 {
+		t.Errorf("Got %v, want an info summary of the 4 dropped messages", summary)
+	}
+	if entries[4].msg != "Request done" {
+		t.Errorf("Got %v, want the message of the new window", entries[4])
+	}
+
+	if got := NewSampling(capture, 0); got != Logger(capture) {
+		t.Errorf("Expected sampling to be disabled for a limit of 0")
+	}
+}